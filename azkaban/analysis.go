@@ -0,0 +1,158 @@
+package azkaban
+
+import (
+	"sort"
+	"time"
+)
+
+// ExecutionAnalysis tells where an execution spent its time and which jobs delayed it.
+type ExecutionAnalysis struct {
+	Status FlowExecutionStatus
+	// Duration is how long the execution ran, until now if it is still running
+	Duration time.Duration
+	// Jobs are the jobs that ran, including the ones of embedded flows, in the order they started
+	Jobs []JobAnalysis
+	// CriticalPath is the chain of jobs that determined how long the execution took, see FlowExecutionStatus.CriticalPath
+	CriticalPath []JobStatus
+}
+
+// JobAnalysis is the timing of a job within an execution.
+type JobAnalysis struct {
+	Job JobStatus
+	// Start is when the job started relative to the start of the execution
+	Start    time.Duration
+	Duration time.Duration
+	// Wait is how long the job waited for Azkaban to start it after its dependencies finished
+	Wait time.Duration
+	// Slack is how much longer the job could have taken without the execution ending later
+	Slack time.Duration
+	// Critical is true for jobs on the critical path
+	Critical bool
+}
+
+// AnalyzeExecution computes the critical path of the execution and the slack of every job. The dependencies between the
+// flow's jobs are taken from graph, the flow's jobs as FlowJobList reports them, so the analysis follows the flow as
+// deployed; jobs not in graph, e.g. those of embedded flows, keep the dependencies of the execution. Jobs that never
+// started, like skipped or disabled ones, are left out.
+func AnalyzeExecution(status FlowExecutionStatus, graph []FlowJob) ExecutionAnalysis {
+	edges := make(map[string][]string)
+	for _, j := range graph {
+		edges[j.ID] = j.In
+	}
+	nodes := make([]JobStatus, len(status.Nodes))
+	for i, n := range status.Nodes {
+		if in, ok := edges[n.ID]; ok {
+			n.In = in
+		}
+		nodes[i] = n
+	}
+	status.Nodes = nodes
+
+	execution := JobStatus{StartTime: status.StartTime, EndTime: status.EndTime}
+	analysis := ExecutionAnalysis{
+		Status:       status,
+		Duration:     execution.Duration(),
+		CriticalPath: status.CriticalPath(),
+	}
+	critical := make(map[string]bool)
+	for _, j := range analysis.CriticalPath {
+		critical[j.QualifiedID()] = true
+	}
+
+	analysis.analyzeNodes(nodes, status.StartTime.Time(), status.StartTime.Time(), execution.end(), critical)
+	sort.SliceStable(analysis.Jobs, func(i, j int) bool { return analysis.Jobs[i].Start < analysis.Jobs[j].Start })
+	return analysis
+}
+
+// analyzeNodes adds the jobs of a flow that started at flowStart and had to end by deadline for the execution not to
+// end later, recursing into embedded flows.
+func (a *ExecutionAnalysis) analyzeNodes(nodes []JobStatus, executionStart time.Time, flowStart time.Time, deadline time.Time, critical map[string]bool) {
+	byID := make(map[string]JobStatus)
+	successors := make(map[string][]JobStatus)
+	for _, n := range nodes {
+		if !n.started() {
+			continue
+		}
+		byID[n.ID] = n
+		for _, dep := range n.In {
+			successors[dep] = append(successors[dep], n)
+		}
+	}
+
+	// ready is when the dependencies of a job had finished, Azkaban starts jobs a little later
+	ready := func(j JobStatus) time.Time {
+		t := flowStart
+		for _, id := range j.In {
+			if dep, ok := byID[id]; ok && dep.end().After(t) {
+				t = dep.end()
+			}
+		}
+		return t
+	}
+
+	slacks := make(map[string]time.Duration)
+	visiting := make(map[string]bool)
+	var slackOf func(j JobStatus) time.Duration
+	slackOf = func(j JobStatus) time.Duration {
+		if s, ok := slacks[j.ID]; ok {
+			return s
+		}
+		slack := deadline.Sub(j.end())
+		// Guards against cycles, which Azkaban doesn't allow anyway
+		if visiting[j.ID] {
+			return slack
+		}
+		visiting[j.ID] = true
+		for _, s := range successors[j.ID] {
+			if d := ready(s).Sub(j.end()) + slackOf(s); d < slack {
+				slack = d
+			}
+		}
+		if slack < 0 {
+			slack = 0
+		}
+		slacks[j.ID] = slack
+		return slack
+	}
+
+	for _, n := range nodes {
+		if !n.started() {
+			continue
+		}
+		slack := slackOf(n)
+		if len(n.Nodes) > 0 {
+			a.analyzeNodes(n.Nodes, executionStart, n.StartTime.Time(), n.end().Add(slack), critical)
+			continue
+		}
+
+		wait := n.StartTime.Time().Sub(ready(n))
+		if wait < 0 {
+			wait = 0
+		}
+
+		a.Jobs = append(a.Jobs, JobAnalysis{
+			Job:      n,
+			Start:    n.StartTime.Time().Sub(executionStart),
+			Duration: n.Duration(),
+			Wait:     wait,
+			Slack:    slack,
+			Critical: critical[n.QualifiedID()],
+		})
+	}
+}
+
+// Bottlenecks returns the n jobs on the critical path that took longest, longest first. Speeding these up is what makes
+// the execution finish sooner.
+func (a ExecutionAnalysis) Bottlenecks(n int) []JobAnalysis {
+	var critical []JobAnalysis
+	for _, j := range a.Jobs {
+		if j.Critical {
+			critical = append(critical, j)
+		}
+	}
+	sort.SliceStable(critical, func(i, j int) bool { return critical[i].Duration > critical[j].Duration })
+	if len(critical) > n {
+		critical = critical[:n]
+	}
+	return critical
+}
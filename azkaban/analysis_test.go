@@ -0,0 +1,63 @@
+package azkaban
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAnalyzeExecution(t *testing.T) {
+	var status FlowExecutionStatus
+	if err := json.Unmarshal([]byte(executionStatusJSON), &status); err != nil {
+		t.Fatal(err)
+	}
+	graph := []FlowJob{
+		{ID: "extract"},
+		{ID: "fetch"},
+		{ID: "process", In: []string{"extract", "fetch"}},
+		{ID: "report", In: []string{"process"}},
+	}
+
+	analysis := AnalyzeExecution(status, graph)
+	if analysis.Duration != 49*time.Second {
+		t.Errorf("expected the execution to take 49s, got %s", analysis.Duration)
+	}
+
+	var timings []string
+	for _, j := range analysis.Jobs {
+		timings = append(timings, fmt.Sprintf("%s %s %s %s %t", j.Job.QualifiedID(), j.Start, j.Duration, j.Slack, j.Critical))
+	}
+	expected := []string{
+		"extract 0s 9s 0s true",
+		"fetch 0s 3s 6s false",
+		"process:clean 9s 10s 0s true",
+		"process:tag 9s 5s 35s false",
+		"process:enrich 19s 30s 0s true",
+	}
+	if !reflect.DeepEqual(timings, expected) {
+		t.Errorf("expected timings %v, got %v", expected, timings)
+	}
+
+	var bottlenecks []string
+	for _, j := range analysis.Bottlenecks(2) {
+		bottlenecks = append(bottlenecks, j.Job.QualifiedID())
+	}
+	if expected := []string{"process:enrich", "process:clean"}; !reflect.DeepEqual(bottlenecks, expected) {
+		t.Errorf("expected bottlenecks %v, got %v", expected, bottlenecks)
+	}
+
+	// The dependencies of the deployed flow win over the ones of the execution
+	graph[2].In = []string{"fetch"}
+	analysis = AnalyzeExecution(status, graph)
+	if path := qualifiedIDs(analysis.CriticalPath); !reflect.DeepEqual(path, []string{"fetch", "process:clean", "process:enrich"}) {
+		t.Errorf("unexpected critical path %v", path)
+	}
+	if extract := analysis.Jobs[0]; extract.Job.ID != "extract" || extract.Slack != 40*time.Second || extract.Critical {
+		t.Errorf("expected extract to have slack until the end of the execution, got %v", extract)
+	}
+	if status.Nodes[2].In[0] != "extract" {
+		t.Errorf("expected the status to be left alone, got %v", status.Nodes[2].In)
+	}
+}
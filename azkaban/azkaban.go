@@ -1,36 +1,66 @@
 package azkaban
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 )
 
-var ErrInvalidSessionID = fmt.Errorf("invalid session id, session might have expired")
-
 func ConnectWithSessionID(url string, sessionID string) (*Client, error) {
 	client := &http.Client{
 		Timeout: time.Second * 30,
 	}
 
-	if !strings.HasSuffix(url, "/") {
-		url = url + "/"
-	}
 	return &Client{
-		http:      client,
-		SessionID: sessionID,
-		url:       url,
+		http:        client,
+		SessionID:   sessionID,
+		url:         normalizeURL(url),
+		RetryPolicy: DefaultRetryPolicy,
 	}, nil
 }
 
 func ConnectWithUsernameAndPassword(u string, username string, password string) (*Client, error) {
+	return ConnectWithUsernameAndPasswordContext(context.Background(), u, username, password)
+}
+
+// ConnectWithUsernameAndPasswordContext is like ConnectWithUsernameAndPassword but logs in with the given context.
+func ConnectWithUsernameAndPasswordContext(ctx context.Context, u string, username string, password string) (*Client, error) {
 	client := &http.Client{
 		Timeout: time.Second * 30,
 	}
 
+	u = normalizeURL(u)
+	sessionID, err := login(ctx, client, u, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		http:        client,
+		SessionID:   sessionID,
+		url:         u,
+		RetryPolicy: DefaultRetryPolicy,
+	}, nil
+}
+
+// SetTimeout sets the timeout for requests to Azkaban, the default is 30 seconds. It applies on top of the deadline of
+// the context passed to the XxxContext methods.
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.http.Timeout = timeout
+}
+
+func normalizeURL(url string) string {
+	if !strings.HasSuffix(url, "/") {
+		url = url + "/"
+	}
+	return url
+}
+
+// login authenticates against Azkaban and returns the new session ID
+func login(ctx context.Context, client *http.Client, u string, username string, password string) (string, error) {
 	form := url.Values{}
 	form.Add("action", "login")
 	form.Add("username", username)
@@ -38,31 +68,35 @@ func ConnectWithUsernameAndPassword(u string, username string, password string)
 
 	req, err := http.NewRequest("POST", u, strings.NewReader(form.Encode()))
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
+	req = req.WithContext(ctx)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", &APIError{Endpoint: "login", Method: "POST", StatusCode: resp.StatusCode, Message: resp.Status, Err: classifyError(resp.StatusCode, "")}
+	}
 	decoder := json.NewDecoder(resp.Body)
 	var status LoginResponse
 	err = decoder.Decode(&status)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
 	if status.Status != "success" {
-		return nil, fmt.Errorf("login failed")
+		message := status.Error
+		if message == "" {
+			message = "login failed"
+		}
+		return "", &APIError{Endpoint: "login", Method: "POST", StatusCode: resp.StatusCode, Message: message, Err: ErrPermissionDenied}
 	}
 
-	return &Client{
-		http:      client,
-		SessionID: status.SessionID,
-		url:       u,
-	}, nil
+	return status.SessionID, nil
 }
@@ -1,17 +1,16 @@
 package azkaban
 
 import (
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	htmlx "golang.org/x/net/html"
 	"html"
 	"io"
-	"io/ioutil"
-	"log"
 	"net/http"
 	"net/http/httputil"
+	"reflect"
 	"strconv"
+	"sync"
 	"time"
 )
 
@@ -20,26 +19,50 @@ type Client struct {
 	http          *http.Client
 	url           string
 	DumpResponses bool
+	// Credentials, if set, are used to log in again once the session expired. Requests that failed because of the
+	// expired session are retried once with the new session.
+	Credentials CredentialsProvider
+	// OnSessionRenewed, if set, is called with the new session ID after the session was renewed.
+	OnSessionRenewed func(sessionID string)
+
+	// RetryPolicy decides whether and when failed requests are retried, see DefaultRetryPolicy.
+	RetryPolicy RetryPolicy
+	// OnRetry, if set, is called before a failed request is retried with the number of the failed attempt, the wait
+	// before the next one, and the cause of the failure.
+	OnRetry func(attempt int, wait time.Duration, err error)
+
+	sessionMutex sync.Mutex
+	rateLimiter  *rateLimiter
 }
 
 func (c *Client) ListProjects() ([]Project, error) {
+	return c.ListProjectsContext(context.Background())
+}
+
+// ListProjectsContext is like ListProjects but sends its requests with the given context.
+func (c *Client) ListProjectsContext(ctx context.Context) ([]Project, error) {
 	params := make(map[string]string)
 	params["ajax"] = "fetchallprojects"
 
 	projects := ListAllProjectsResponse{}
-	if err := c.requestAndDecode("GET", "index", params, &projects); err != nil {
+	if err := c.requestAndDecode(ctx, "GET", "index", params, &projects); err != nil {
 		return nil, err
 	}
 	return projects.Projects, nil
 }
 
 func (c *Client) ListFlows(project string) ([]Flow, error) {
+	return c.ListFlowsContext(context.Background(), project)
+}
+
+// ListFlowsContext is like ListFlows but sends its requests with the given context.
+func (c *Client) ListFlowsContext(ctx context.Context, project string) ([]Flow, error) {
 	params := make(map[string]string)
 	params["ajax"] = "fetchprojectflows"
 	params["project"] = project
 
 	flows := ListFlowsResponse{}
-	if err := c.requestAndDecode("GET", "manager", params, &flows); err != nil {
+	if err := c.requestAndDecode(ctx, "GET", "manager", params, &flows); err != nil {
 		return nil, err
 	}
 
@@ -48,10 +71,15 @@ func (c *Client) ListFlows(project string) ([]Flow, error) {
 
 // FetchLogsUntilEnd fetchs all logs from the given offset till the end and outputs them to the given writer
 func (c *Client) FetchLogsUntilEnd(executionID int64, jobID string, offset int64, writer io.Writer) (int64, error) {
+	return c.FetchLogsUntilEndContext(context.Background(), executionID, jobID, offset, writer)
+}
+
+// FetchLogsUntilEndContext is like FetchLogsUntilEnd but sends its requests with the given context.
+func (c *Client) FetchLogsUntilEndContext(ctx context.Context, executionID int64, jobID string, offset int64, writer io.Writer) (int64, error) {
 	fetchLength := int64(1024 * 512)
 	currentOffset := offset
 	for {
-		log, err := c.FetchExecutionJobLog(executionID, jobID, currentOffset, fetchLength)
+		log, err := c.FetchExecutionJobLogContext(ctx, executionID, jobID, currentOffset, fetchLength)
 		if err != nil {
 			return 0, err
 		}
@@ -67,6 +95,11 @@ func (c *Client) FetchLogsUntilEnd(executionID int64, jobID string, offset int64
 }
 
 func (c *Client) FetchExecutionJobLog(executionID int64, jobID string, offset int64, length int64) (FlowJobLog, error) {
+	return c.FetchExecutionJobLogContext(context.Background(), executionID, jobID, offset, length)
+}
+
+// FetchExecutionJobLogContext is like FetchExecutionJobLog but sends its requests with the given context.
+func (c *Client) FetchExecutionJobLogContext(ctx context.Context, executionID int64, jobID string, offset int64, length int64) (FlowJobLog, error) {
 	params := make(map[string]string)
 	params["ajax"] = "fetchExecJobLogs"
 	params["execid"] = fmt.Sprintf("%d", executionID)
@@ -75,18 +108,16 @@ func (c *Client) FetchExecutionJobLog(executionID int64, jobID string, offset in
 	params["length"] = fmt.Sprintf("%d", length)
 
 	log := FlowJobLog{}
-	resp, err := c.request("GET", "executor", params)
-
-	if err != nil {
-		return log, err
-	}
-
-	decoder := json.NewDecoder(resp.Body)
-	err = decoder.Decode(&log)
+	err := c.requestAndDecode(ctx, "GET", "executor", params, &log)
 	return log, err
 }
 
 func (c *Client) FlowExecutions(project, flow string, paginator Paginator) (Executions, error) {
+	return c.FlowExecutionsContext(context.Background(), project, flow, paginator)
+}
+
+// FlowExecutionsContext is like FlowExecutions but sends its requests with the given context.
+func (c *Client) FlowExecutionsContext(ctx context.Context, project, flow string, paginator Paginator) (Executions, error) {
 	params := make(map[string]string)
 	params["ajax"] = "fetchFlowExecutions"
 	params["project"] = project
@@ -95,7 +126,7 @@ func (c *Client) FlowExecutions(project, flow string, paginator Paginator) (Exec
 	params["length"] = strconv.Itoa(paginator.Length)
 
 	executions := ExecutionsList{}
-	if err := c.requestAndDecode("GET", "manager", params, &executions); err != nil {
+	if err := c.requestAndDecode(ctx, "GET", "manager", params, &executions); err != nil {
 		return nil, err
 	}
 
@@ -103,254 +134,315 @@ func (c *Client) FlowExecutions(project, flow string, paginator Paginator) (Exec
 }
 
 func (c *Client) FlowJobList(project, flow string) (FlowJobList, error) {
+	return c.FlowJobListContext(context.Background(), project, flow)
+}
+
+// FlowJobListContext is like FlowJobList but sends its requests with the given context.
+func (c *Client) FlowJobListContext(ctx context.Context, project, flow string) (FlowJobList, error) {
 	params := make(map[string]string)
 	params["ajax"] = "fetchflowgraph"
 	params["project"] = project
 	params["flow"] = flow
 
 	jobList := FlowJobList{}
-	err := c.requestAndDecode("GET", "manager", params, &jobList)
+	err := c.requestAndDecode(ctx, "GET", "manager", params, &jobList)
 	return jobList, err
 }
 
-func (c *Client) RestartFlowNow(project, flow string) error {
+// JobInfo returns the properties of the given job of a deployed flow.
+func (c *Client) JobInfo(project, flow, job string) (JobInfo, error) {
+	return c.JobInfoContext(context.Background(), project, flow, job)
+}
+
+// JobInfoContext is like JobInfo but sends its requests with the given context.
+func (c *Client) JobInfoContext(ctx context.Context, project, flow, job string) (JobInfo, error) {
 	params := make(map[string]string)
-	params["ajax"] = "executeFlow"
+	params["ajax"] = "fetchJobInfo"
 	params["project"] = project
-	params["flow"] = flow
+	params["flowName"] = flow
+	params["jobName"] = job
 
-	resp, err := c.request("GET", "executor", params)
+	info := JobInfo{}
+	err := c.requestAndDecode(ctx, "GET", "manager", params, &info)
+	return info, err
+}
+
+// ExecuteFlow submits a new execution of the given flow with the given options and returns Azkaban's response which
+// includes the ID of the new execution.
+func (c *Client) ExecuteFlow(project, flow string, options ExecutionOptions) (ExecuteFlowResponse, error) {
+	return c.ExecuteFlowContext(context.Background(), project, flow, options)
+}
+
+// ExecuteFlowContext is like ExecuteFlow but sends its requests with the given context.
+func (c *Client) ExecuteFlowContext(ctx context.Context, project, flow string, options ExecutionOptions) (ExecuteFlowResponse, error) {
+	result := ExecuteFlowResponse{}
+	params, err := options.params()
 	if err != nil {
-		return err
+		return result, err
 	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return err
+	params["ajax"] = "executeFlow"
+	params["project"] = project
+	params["flow"] = flow
+
+	err = c.requestAndDecode(ctx, "GET", "executor", params, &result)
+	return result, err
+}
+
+// CancelExecution cancels the running execution with the given ID.
+func (c *Client) CancelExecution(executionID int64) error {
+	return c.CancelExecutionContext(context.Background(), executionID)
+}
+
+// CancelExecutionContext is like CancelExecution but sends its requests with the given context.
+func (c *Client) CancelExecutionContext(ctx context.Context, executionID int64) error {
+	return c.executionAction(ctx, "cancelFlow", executionID)
+}
+
+// PauseExecution pauses the running execution with the given ID. Jobs that are already running will finish but no new
+// jobs are started until the execution is resumed.
+func (c *Client) PauseExecution(executionID int64) error {
+	return c.PauseExecutionContext(context.Background(), executionID)
+}
+
+// PauseExecutionContext is like PauseExecution but sends its requests with the given context.
+func (c *Client) PauseExecutionContext(ctx context.Context, executionID int64) error {
+	return c.executionAction(ctx, "pauseFlow", executionID)
+}
+
+// ResumeExecution resumes the paused execution with the given ID.
+func (c *Client) ResumeExecution(executionID int64) error {
+	return c.ResumeExecutionContext(context.Background(), executionID)
+}
+
+// ResumeExecutionContext is like ResumeExecution but sends its requests with the given context.
+func (c *Client) ResumeExecutionContext(ctx context.Context, executionID int64) error {
+	return c.executionAction(ctx, "resumeFlow", executionID)
+}
+
+func (c *Client) executionAction(ctx context.Context, action string, executionID int64) error {
+	params := make(map[string]string)
+	params["ajax"] = action
+	params["execid"] = fmt.Sprintf("%d", executionID)
+
+	resp := AzkabanResponse{}
+	return c.requestAndDecode(ctx, "GET", "executor", params, &resp)
+}
+
+// RunningExecutions returns the IDs of all currently running executions of the given flow.
+func (c *Client) RunningExecutions(project, flow string) ([]int64, error) {
+	return c.RunningExecutionsContext(context.Background(), project, flow)
+}
+
+// RunningExecutionsContext is like RunningExecutions but sends its requests with the given context.
+func (c *Client) RunningExecutionsContext(ctx context.Context, project, flow string) ([]int64, error) {
+	params := make(map[string]string)
+	params["ajax"] = "getRunning"
+	params["project"] = project
+	params["flow"] = flow
+
+	running := RunningExecutionsResponse{}
+	if err := c.requestAndDecode(ctx, "GET", "executor", params, &running); err != nil {
+		return nil, err
 	}
-	log.Printf("%s", body)
 
-	return nil
+	return running.ExecutionIDs, nil
 }
 
 func (c *Client) FlowExecutionStatus(executionID int64) (FlowExecutionStatus, error) {
+	return c.FlowExecutionStatusContext(context.Background(), executionID)
+}
+
+// FlowExecutionStatusContext is like FlowExecutionStatus but sends its requests with the given context.
+func (c *Client) FlowExecutionStatusContext(ctx context.Context, executionID int64) (FlowExecutionStatus, error) {
 	status := FlowExecutionStatus{}
 
 	params := make(map[string]string)
 	params["ajax"] = "fetchexecflow"
 	params["execid"] = fmt.Sprintf("%d", executionID)
 
-	resp, err := c.request("GET", "executor", params)
-	if err != nil {
-		return status, err
-	}
-	defer resp.Body.Close()
-
-	decoder := json.NewDecoder(resp.Body)
-	err = decoder.Decode(&status)
+	err := c.requestAndDecode(ctx, "GET", "executor", params, &status)
 	return status, err
 }
 
+// ExecutionInfo returns the options the given execution was submitted with.
+func (c *Client) ExecutionInfo(executionID int64) (ExecutionInfo, error) {
+	return c.ExecutionInfoContext(context.Background(), executionID)
+}
+
+// ExecutionInfoContext is like ExecutionInfo but sends its requests with the given context.
+func (c *Client) ExecutionInfoContext(ctx context.Context, executionID int64) (ExecutionInfo, error) {
+	params := make(map[string]string)
+	params["ajax"] = "flowInfo"
+	params["execid"] = fmt.Sprintf("%d", executionID)
+
+	info := ExecutionInfo{}
+	err := c.requestAndDecode(ctx, "GET", "executor", params, &info)
+	return info, err
+}
+
 func (c *Client) FlowSchedule(projectID int64, flowID string) (FlowSchedule, error) {
+	return c.FlowScheduleContext(context.Background(), projectID, flowID)
+}
+
+// FlowScheduleContext is like FlowSchedule but sends its requests with the given context.
+func (c *Client) FlowScheduleContext(ctx context.Context, projectID int64, flowID string) (FlowSchedule, error) {
 	params := make(map[string]string)
 	params["ajax"] = "fetchSchedule"
 	params["projectId"] = fmt.Sprintf("%d", projectID)
 	params["flowId"] = flowID
 
-	res, err := c.request("GET", "schedule", params)
-	if err != nil {
-		return FlowSchedule{}, err
-	}
-	defer res.Body.Close()
-	if c.DumpResponses {
-		b, err := httputil.DumpResponse(res, true)
-		if err != nil {
-			return FlowSchedule{}, err
-		}
-
-		fmt.Printf("%s %s: \n", res.Request.Method, res.Request.URL.String())
-		fmt.Printf("%s\n", b)
-	}
-
-	decoder := json.NewDecoder(res.Body)
 	resp := ScheduleResponse{}
-	err = decoder.Decode(&resp)
-	if err != nil {
+	if err := c.requestAndDecode(ctx, "GET", "schedule", params, &resp); err != nil {
 		return FlowSchedule{}, err
 	}
 
 	if resp.Empty() {
 		return FlowSchedule{}, nil
 	} else {
-		return *resp.Schedule, err
+		return *resp.Schedule, nil
 	}
 }
 
-func (c *Client) Running() ([]FlowExecution, error) {
-	resp, err := c.request("GET", "executor", nil)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != 200 {
-		return nil, errors.New(fmt.Sprintf("got %d %s when retrieving running executions", resp.StatusCode, resp.Status))
-	}
+// ScheduleCronFlow schedules the given flow with a Quartz cron expression, e.g. "0 0 2 ? * MON-FRI". Azkaban evaluates
+// the expression in the server's timezone. An existing schedule of the flow is replaced.
+func (c *Client) ScheduleCronFlow(project, flow, cronExpression string, options ExecutionOptions) (ScheduleFlowResponse, error) {
+	return c.ScheduleCronFlowContext(context.Background(), project, flow, cronExpression, options)
+}
 
-	defer resp.Body.Close()
-	doc, err := htmlx.Parse(resp.Body)
+// ScheduleCronFlowContext is like ScheduleCronFlow but sends its requests with the given context.
+func (c *Client) ScheduleCronFlowContext(ctx context.Context, project, flow, cronExpression string, options ExecutionOptions) (ScheduleFlowResponse, error) {
+	result := ScheduleFlowResponse{}
+	params, err := options.params()
 	if err != nil {
-		return nil, err
-	}
-
-	// Azkaban serves the login page simply with a HTTP 200 so the only way to check if we're looking at the login page
-	// is by looking for the login element.
-	if findElementWithID(doc, "username") != nil && findElementWithID(doc, "password") != nil {
-		return nil, errors.New("credentials expired, reauthenticate")
+		return result, err
 	}
+	params["ajax"] = "scheduleCronFlow"
+	params["projectName"] = project
+	params["flow"] = flow
+	params["cronExpression"] = cronExpression
 
-	table := findElementWithID(doc, "executingJobs")
-
-	return findExecutions(table)
+	err = c.requestAndDecode(ctx, "POST", "schedule", params, &result)
+	return result, err
 }
 
-type FlowExecution struct {
-	FlowID  string
-	Project string
-	Execution
+// RemoveSchedule removes the schedule with the given ID.
+func (c *Client) RemoveSchedule(scheduleID string) error {
+	return c.RemoveScheduleContext(context.Background(), scheduleID)
 }
 
-func findExecutions(n *htmlx.Node) ([]FlowExecution, error) {
-	tbody := findElementsOfType(n, "tbody")
-	rows := findElementsOfType(tbody[0], "tr")
-
-	var executions []FlowExecution
-	for _, row := range rows {
-		cells := findElementsOfType(row, "td")
-
-		execID, err := strconv.ParseInt(findElementsOfType(cells[1], "a")[0].FirstChild.Data, 10, 64)
-		if err != nil {
-			return nil, err
-		}
-		flowID := findElementsOfType(cells[3], "a")[0].FirstChild.Data
-		project := findElementsOfType(cells[4], "a")[0].FirstChild.Data
-		// TODO project ID is not part of the actual link so we can't parse it. We'll have to fetch projects before we do this
-		// parsing time "2019-08-21 01:53:42" as "Jan 2, 2006 at 3:04pm": cannot parse "2019-08-21 01:53:42" as "Jan"
-		startTime, err := time.Parse("2006-01-02 15:04:05", cells[7].FirstChild.Data)
-		if err != nil {
-			return nil, err
-		}
-
-		execution := FlowExecution{
-			FlowID:  flowID,
-			Project: project,
-			Execution: Execution{
-				SubmitTime: AzkabanTimestamp{},
-				StartTime:  AzkabanTimestamp(startTime),
-				Status:     "RUNNING",
-				ID:         execID,
-				EndTime:    AzkabanTimestamp(time.Now()),
-			},
-		}
+// RemoveScheduleContext is like RemoveSchedule but sends its requests with the given context.
+func (c *Client) RemoveScheduleContext(ctx context.Context, scheduleID string) error {
+	params := make(map[string]string)
+	params["action"] = "removeSched"
+	params["scheduleId"] = scheduleID
 
-		executions = append(executions, execution)
-	}
+	result := ScheduleFlowResponse{}
+	return c.requestAndDecode(ctx, "POST", "schedule", params, &result)
+}
 
-	return executions, nil
+// Schedules returns all schedules of all projects.
+func (c *Client) Schedules() ([]ScheduledFlow, error) {
+	return c.SchedulesContext(context.Background())
 }
 
-func findElementsOfType(n *htmlx.Node, t string) []*htmlx.Node {
-	var result []*htmlx.Node
-	if n.Type == htmlx.ElementNode && n.Data == t {
-		result = append(result, n)
-	}
+// SchedulesContext is like Schedules but sends its requests with the given context.
+func (c *Client) SchedulesContext(ctx context.Context) ([]ScheduledFlow, error) {
+	params := make(map[string]string)
+	params["ajax"] = "loadFlow"
 
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		result = append(result, findElementsOfType(c, t)...)
+	schedules := ListSchedulesResponse{}
+	if err := c.requestAndDecode(ctx, "GET", "schedule", params, &schedules); err != nil {
+		return nil, err
 	}
 
-	return result
+	return schedules.Schedules, nil
 }
 
-func findElementWithID(n *htmlx.Node, id string) *htmlx.Node {
-	if hasAttribute(n, "id", id) {
-		return n
-	}
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		if result := findElementWithID(c, id); result != nil {
-			return result
-		}
-	}
-	return nil
-}
+func (c *Client) requestAndDecode(ctx context.Context, method string, path string, params map[string]string, dst interface{}) error {
+	return c.withSession(ctx, func() error {
+		// Reset dst so a retried request doesn't see fields of the failed response
+		v := reflect.ValueOf(dst).Elem()
+		v.Set(reflect.Zero(v.Type()))
 
-func hasAttribute(n *htmlx.Node, name string, value string) bool {
-	for _, a := range n.Attr {
-		if a.Key == name && a.Val == value {
-			return true
-		}
-	}
-	return false
+		return c.doRequestAndDecode(ctx, method, path, params, dst)
+	})
 }
 
-func (c *Client) requestAndDecode(method string, path string, params map[string]string, dst interface{}) error {
-	resp, err := c.request(method, path, params)
+func (c *Client) doRequestAndDecode(ctx context.Context, method string, path string, params map[string]string, dst interface{}) error {
+	resp, err := c.request(ctx, method, path, params)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	if c.DumpResponses {
-		b, err := httputil.DumpResponse(resp, true)
-		if err != nil {
-			return err
-		}
+	return decodeResponse(method, path, params, resp, dst)
+}
 
-		fmt.Printf("%s %s: \n", method, resp.Request.URL.String())
-		fmt.Printf("%s\n", b)
+// decodeResponse decodes the JSON response into dst and returns the error Azkaban reported, if any.
+func decodeResponse(method string, path string, params map[string]string, resp *http.Response, dst interface{}) error {
+	if resp.StatusCode != http.StatusOK {
+		// Azkaban sometimes explains the status with an error in the body
+		errResp := AzkabanResponse{}
+		json.NewDecoder(resp.Body).Decode(&errResp)
+		return newAPIError(method, path, params, resp.StatusCode, errResp.Error)
 	}
 
-	decoder := json.NewDecoder(resp.Body)
-	err = decoder.Decode(dst)
-	if err != nil {
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
 		return err
 	}
 
-	azkabanResp, ok := dst.(AzkabanError)
-	if !ok {
-		log.Printf("non-azkaban response for %s %s", method, resp.Request.URL)
-		x, _ := httputil.DumpResponse(resp, true)
-		log.Printf("%s", x)
-		return fmt.Errorf("Bug: not an azakaban response")
-	}
-	if azkabanResp.AzkabanError() == "session" {
-		return ErrInvalidSessionID
+	if azkabanResp, ok := dst.(AzkabanError); ok && azkabanResp.AzkabanError() != "" {
+		return newAPIError(method, path, params, resp.StatusCode, azkabanResp.AzkabanError())
 	}
 
 	return nil
+}
 
+// request sends a request to Azkaban, retrying it according to the client's retry policy.
+func (c *Client) request(ctx context.Context, method string, path string, params map[string]string) (*http.Response, error) {
+	return c.withRetries(ctx, method, path, params, func() (*http.Response, error) {
+		return c.doRequest(ctx, method, path, params)
+	})
 }
 
-func (c *Client) request(method string, path string, params map[string]string) (*http.Response, error) {
+func (c *Client) doRequest(ctx context.Context, method string, path string, params map[string]string) (*http.Response, error) {
 	req, err := http.NewRequest(method, c.url+path, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	q := req.URL.Query()
-	q.Add("session.id", c.SessionID)
+	q.Add("session.id", c.currentSessionID())
 	for k, v := range params {
 		q.Add(k, v)
 	}
 	req.URL.RawQuery = q.Encode()
+
+	return c.send(ctx, req)
+}
+
+// send sends the given request once the rate limit allows it.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	if c.rateLimiter != nil {
+		if err := c.rateLimiter.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
 	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
 	if c.DumpResponses {
 		b, err := httputil.DumpResponse(resp, true)
 		if err != nil {
+			resp.Body.Close()
 			return nil, err
 		}
 
-		fmt.Printf("%s %s: \n", method, resp.Request.URL.String())
+		fmt.Printf("%s %s: \n", req.Method, resp.Request.URL.String())
 		fmt.Printf("%s\n", b)
 	}
 
-	return resp, err
+	return resp, nil
 }
@@ -0,0 +1,694 @@
+package azkaban_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/azkabantest"
+)
+
+// newServer starts a fake server with project "example" containing flow "daily" with jobs extract -> transform -> load.
+func newServer(t *testing.T) *azkabantest.Server {
+	s := azkabantest.NewServer()
+	s.AddProject("example")
+	s.AddFlow("example", "daily",
+		azkaban.FlowJob{ID: "extract", Type: "command"},
+		azkaban.FlowJob{ID: "transform", Type: "command", In: []string{"extract"}},
+		azkaban.FlowJob{ID: "load", Type: "command", In: []string{"transform"}},
+	)
+	return s
+}
+
+func TestListProjectsAndFlows(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.AddProject("other")
+	client := s.Client()
+
+	projects, err := client.ListProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].Name != "example" || projects[1].Name != "other" {
+		t.Errorf("unexpected projects %v", projects)
+	}
+
+	flows, err := client.ListFlows("example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flows) != 1 || flows[0].FlowID != "daily" {
+		t.Errorf("unexpected flows %v", flows)
+	}
+}
+
+func TestFlowJobList(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	jobs, err := s.Client().FlowJobList("example", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs.Nodes) != 3 || jobs.Nodes[2].ID != "load" || !reflect.DeepEqual(jobs.Nodes[2].In, []string{"transform"}) {
+		t.Errorf("unexpected jobs %v", jobs.Nodes)
+	}
+}
+
+func TestJobInfo(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.Project("example").Flows[0].Properties["transform"] = map[string]string{"command": "transform.sh"}
+
+	info, err := s.Client().JobInfo("example", "daily", "transform")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"type": "command", "dependencies": "extract", "command": "transform.sh"}
+	if info.JobName != "transform" || info.JobType != "command" || !reflect.DeepEqual(info.GeneralParams, expected) {
+		t.Errorf("unexpected job info %v", info)
+	}
+
+	if _, err := s.Client().JobInfo("example", "daily", "missing"); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found error, got %v", err)
+	}
+}
+
+func TestFlowExecutions(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	start := time.Date(2019, time.August, 1, 10, 0, 0, 0, time.UTC)
+	s.AddExecution("example", "daily", "FAILED", start)
+	s.AddExecution("example", "daily", "SUCCEEDED", start.Add(24*time.Hour))
+	s.AddExecution("example", "daily", "RUNNING", start.Add(48*time.Hour))
+
+	executions, err := s.Client().FlowExecutions("example", "daily", azkaban.NMostRecent(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(executions) != 2 {
+		t.Fatalf("expected 2 executions, got %d", len(executions))
+	}
+	if executions[0].Status != "RUNNING" || executions[1].Status != "SUCCEEDED" {
+		t.Errorf("expected most recent executions first, got %v", executions)
+	}
+	if !executions[1].StartTime.Time().Equal(start.Add(24 * time.Hour)) {
+		t.Errorf("unexpected start time %s", executions[1].StartTime.Time())
+	}
+}
+
+func TestFetchLogs(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "FAILED", time.Now())
+	e.Job("load").Log = "line 1\nline <2>\n"
+
+	var buf bytes.Buffer
+	offset, err := s.Client().FetchLogsUntilEnd(e.ID, "load", 0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "line 1\nline <2>\n" {
+		t.Errorf("unexpected log %q", buf.String())
+	}
+	if offset != int64(len(e.Job("load").Log)) {
+		t.Errorf("unexpected offset %d", offset)
+	}
+
+	log, err := s.Client().FetchExecutionJobLog(e.ID, "load", 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Data != "1\nl" || log.Offset != 5 || log.Length != 3 {
+		t.Errorf("unexpected log chunk %v", log)
+	}
+}
+
+func TestExecuteFlowAndExecutionInfo(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	options := azkaban.ExecutionOptions{
+		FlowParameters:   map[string]string{"date": "2019-08-01"},
+		DisabledJobs:     []string{"extract"},
+		FailureAction:    azkaban.FinishPossible,
+		ConcurrentOption: azkaban.ConcurrentPipeline,
+		PipelineLevel:    2,
+		FailureEmails:    []string{"oncall@example.com"},
+	}
+	resp, err := client.ExecuteFlow("example", "daily", options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ExecutionID == 0 || resp.Flow != "daily" {
+		t.Fatalf("unexpected response %v", resp)
+	}
+
+	info, err := client.ExecutionInfo(resp.ExecutionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info.ExecutionOptions(), options) {
+		t.Errorf("expected options %v, got %v", options, info.ExecutionOptions())
+	}
+	if info.NodeStatus["extract"] != "DISABLED" {
+		t.Errorf("expected extract to be disabled, got %s", info.NodeStatus["extract"])
+	}
+
+	if _, err := client.ExecuteFlow("example", "missing", azkaban.ExecutionOptions{}); err == nil {
+		t.Error("expected error for missing flow")
+	}
+}
+
+func TestFlowExecutionStatus(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "FAILED", time.Now())
+
+	status, err := s.Client().FlowExecutionStatus(e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Status != "FAILED" || status.Project != "example" || status.FlowID != "daily" {
+		t.Errorf("unexpected status %v", status)
+	}
+	var nodes []string
+	for _, n := range status.Nodes {
+		nodes = append(nodes, fmt.Sprintf("%s %s", n.ID, n.Status))
+	}
+	expected := []string{"extract SUCCEEDED", "transform SUCCEEDED", "load FAILED"}
+	if !reflect.DeepEqual(nodes, expected) {
+		t.Errorf("expected nodes %v, got %v", expected, nodes)
+	}
+	if load := status.Nodes[2]; load.Type != "command" || !reflect.DeepEqual(load.In, []string{"transform"}) || load.EndTime.Time().Before(load.StartTime.Time()) {
+		t.Errorf("unexpected job status %v", load)
+	}
+}
+
+func TestFlowExecutionUpdate(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "RUNNING", time.Now().Add(-time.Minute))
+
+	status, err := s.Client().FlowExecutionStatus(e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Azkaban's update times have millisecond precision
+	time.Sleep(2 * time.Millisecond)
+	s.UpdateJob(e.ID, "extract", "SUCCEEDED")
+	update, err := s.Client().FlowExecutionUpdate(e.ID, status.UpdateTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(update.Nodes) != 1 || update.Nodes[0].ID != "extract" {
+		t.Errorf("expected only the changed job, got %v", update.Nodes)
+	}
+	status.Apply(update)
+	if status.Nodes[0].Status != "SUCCEEDED" || status.Nodes[1].Status != "RUNNING" || status.Status != "RUNNING" {
+		t.Errorf("unexpected status after update %v", status)
+	}
+
+	s.UpdateJob(e.ID, "transform", "SUCCEEDED")
+	s.UpdateJob(e.ID, "load", "FAILED")
+	update, err = s.Client().FlowExecutionUpdate(e.ID, azkaban.AzkabanTimestamp{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status.Apply(update)
+	if len(update.Nodes) != 3 || status.Status != "FAILED" || status.Nodes[2].Status != "FAILED" {
+		t.Errorf("expected all jobs without last update time and the execution to fail, got %v", update)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	_, err := client.ListFlows("missing")
+	if !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found for missing project, got %v", err)
+	}
+	if err.Error() != "GET manager?ajax=fetchprojectflows: Project missing doesn't exist." {
+		t.Errorf("unexpected error message %q", err)
+	}
+	if _, err := client.FlowJobList("example", "missing"); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found for missing flow, got %v", err)
+	}
+	if _, err := client.FlowExecutionStatus(404); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found for missing execution, got %v", err)
+	}
+	if err := client.RemoveSchedule("404"); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found for missing schedule, got %v", err)
+	}
+}
+
+func TestHTTPErrors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down for maintenance", http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+	client, _ := azkaban.ConnectWithSessionID(s.URL, "session")
+	client.RetryPolicy = azkaban.NoRetries
+
+	_, err := client.ListProjects()
+	apiErr, ok := err.(*azkaban.APIError)
+	if !ok || apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Endpoint != "index?ajax=fetchallprojects" {
+		t.Errorf("expected API error for HTTP 503, got %#v", err)
+	}
+
+	// Connection errors are returned even while dumping responses
+	s.Close()
+	client.DumpResponses = true
+	if _, err := client.ListProjects(); err == nil {
+		t.Error("expected connection error")
+	}
+}
+
+func TestRetries(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+	client.RetryPolicy = azkaban.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	var retries []error
+	client.OnRetry = func(attempt int, wait time.Duration, err error) { retries = append(retries, err) }
+
+	s.FailNext(2, http.StatusServiceUnavailable)
+	if _, err := client.ListProjects(); err != nil {
+		t.Fatal(err)
+	}
+	if len(retries) != 2 || retries[0].Error() != "GET index?ajax=fetchallprojects: 503 Service Unavailable" {
+		t.Errorf("expected 2 retries, got %v", retries)
+	}
+
+	retries = nil
+	s.FailNext(3, http.StatusBadGateway)
+	_, err := client.ListFlows("example")
+	if apiErr, ok := err.(*azkaban.APIError); !ok || apiErr.StatusCode != http.StatusBadGateway || len(retries) != 2 {
+		t.Errorf("expected to give up after 3 attempts, got %v after %d retries", err, len(retries))
+	}
+
+	// Changes to executions are not retried
+	retries = nil
+	e := s.AddExecution("example", "daily", "RUNNING", time.Now())
+	s.FailNext(1, http.StatusServiceUnavailable)
+	if err := client.CancelExecution(e.ID); err == nil || len(retries) != 0 {
+		t.Errorf("expected cancel to fail without retries, got %v after %d retries", err, len(retries))
+	}
+
+	// Neither are project deletes, which are GET requests without an ajax action
+	s.FailNextMatching(1, http.StatusBadGateway, func(r *http.Request) bool { return r.FormValue("delete") == "true" })
+	err = client.DeleteProject("example")
+	if apiErr, ok := err.(*azkaban.APIError); !ok || apiErr.StatusCode != http.StatusBadGateway || len(retries) != 0 {
+		t.Errorf("expected delete to fail without retries, got %v after %d retries", err, len(retries))
+	}
+}
+
+func TestDeployProject(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	if err := client.CreateProject("new", "a new project"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CreateProject("new", "again"); err == nil {
+		t.Error("expected error creating an existing project")
+	}
+
+	var zipFile bytes.Buffer
+	archive := zip.NewWriter(&zipFile)
+	w, _ := archive.Create("daily.job")
+	w.Write([]byte("type=command\ncommand=echo daily\n"))
+	archive.Close()
+
+	// The upload is repeated after the session was renewed
+	s.ExpireSessions()
+	client.Credentials = azkaban.StaticCredentials(azkabantest.DefaultUsername, azkabantest.DefaultPassword)
+	result, err := client.UploadProjectZip("new", bytes.NewReader(zipFile.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Version != "1" || result.Warnings != "" {
+		t.Errorf("unexpected upload result %v", result)
+	}
+	versions := s.Project("new").Versions
+	if len(versions) != 1 || !bytes.Equal(versions[0].Zip, zipFile.Bytes()) {
+		t.Errorf("expected uploaded zip to be stored, got %v", versions)
+	}
+	if _, err := client.UploadProjectZip("missing", bytes.NewReader(zipFile.Bytes())); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found uploading to a missing project, got %v", err)
+	}
+
+	var emptyZip bytes.Buffer
+	zip.NewWriter(&emptyZip).Close()
+	if _, err := client.UploadProjectZip("new", &emptyZip); err != nil {
+		t.Fatal(err)
+	}
+	projectVersions, err := client.ProjectVersions("new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projectVersions) != 2 || projectVersions[0].Version != 2 || projectVersions[1].UploadUser != azkabantest.DefaultUsername {
+		t.Errorf("unexpected versions %v", projectVersions)
+	}
+	// Versions are counted from the whole log, not only the first page Azkaban returns
+	for i := 0; i < 2000; i++ {
+		s.Project("new").Versions = append(s.Project("new").Versions, &azkabantest.ProjectVersion{Version: i + 3, UploadUser: "bulk", UploadTime: time.Now()})
+	}
+	if projectVersions, err := client.ProjectVersions("new"); err != nil || len(projectVersions) != 2002 || projectVersions[0].Version != 2002 || projectVersions[2001].Version != 1 {
+		t.Errorf("expected versions of all uploads, got %d, %v", len(projectVersions), err)
+	}
+	s.Project("new").Versions = s.Project("new").Versions[:2]
+
+	downloaded, err := client.DownloadProject("new", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(downloaded.Content, zipFile.Bytes()) {
+		t.Error("expected to download the first version")
+	}
+	// The latest version is left to Azkaban rather than numbered from the log, which may be incomplete
+	s.Project("new").Versions[1].FileName = "flows.zip"
+	if downloaded, err := client.DownloadProject("new", 0); err != nil || bytes.Equal(downloaded.Content, zipFile.Bytes()) || downloaded.FileName != "flows.zip" {
+		t.Errorf("expected to download the latest version, got %q, %v", downloaded.FileName, err)
+	}
+	if _, err := client.DownloadProject("new", 3); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found downloading a missing version, got %v", err)
+	}
+
+	if err := client.DeleteProject("new"); err != nil {
+		t.Fatal(err)
+	}
+	if s.Project("new") != nil {
+		t.Error("expected project to be deleted")
+	}
+	if err := client.DeleteProject("new"); !azkaban.IsNotFound(err) {
+		t.Errorf("expected not found deleting a missing project, got %v", err)
+	}
+}
+
+func TestExecutionActions(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+	e := s.AddExecution("example", "daily", "RUNNING", time.Now())
+
+	if err := client.PauseExecution(e.ID); err != nil {
+		t.Fatal(err)
+	}
+	if e.Status != "PAUSED" {
+		t.Errorf("expected PAUSED, got %s", e.Status)
+	}
+	if err := client.ResumeExecution(e.ID); err != nil {
+		t.Fatal(err)
+	}
+	if e.Status != "RUNNING" {
+		t.Errorf("expected RUNNING, got %s", e.Status)
+	}
+	if err := client.CancelExecution(e.ID); err != nil {
+		t.Fatal(err)
+	}
+	if e.Status != "KILLED" {
+		t.Errorf("expected KILLED, got %s", e.Status)
+	}
+	err := client.CancelExecution(e.ID)
+	if apiErr, ok := err.(*azkaban.APIError); !ok || apiErr.Endpoint != "executor?ajax=cancelFlow" || apiErr.Err != nil {
+		t.Errorf("expected API error cancelling a finished execution, got %#v", err)
+	}
+}
+
+func TestRunning(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.AddProject("other")
+	s.AddFlow("other", "hourly", azkaban.FlowJob{ID: "only", Type: "noop"})
+	s.AddExecution("example", "daily", "SUCCEEDED", time.Now())
+	running := s.AddExecution("example", "daily", "RUNNING", time.Now())
+	other := s.AddExecution("other", "hourly", "RUNNING", time.Now())
+	client := s.Client()
+
+	ids, err := client.RunningExecutions("example", "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int64{running.ID}) {
+		t.Errorf("unexpected running executions %v", ids)
+	}
+
+	all, err := client.Running()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].ID != running.ID || all[1].ID != other.ID || all[1].Project != "other" {
+		t.Errorf("unexpected running executions %v", all)
+	}
+
+	inProject, err := client.RunningInProject("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inProject) != 1 || inProject[0].FlowID != "hourly" {
+		t.Errorf("unexpected running executions %v", inProject)
+	}
+
+	// Projects the user may not read are skipped, unless asked for explicitly
+	s.Project("other").Denied = true
+	all, err = client.Running()
+	if err != nil || len(all) != 1 || all[0].ID != running.ID {
+		t.Errorf("expected only the running execution of example, got %v, %v", all, err)
+	}
+	if _, err := client.RunningInProject("other"); !azkaban.IsPermissionDenied(err) {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+}
+
+func TestRetryFailedJobs(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+	failed := s.AddExecution("example", "daily", "FAILED", time.Now())
+
+	plan, err := client.PlanRetry(failed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan.Options.DisabledJobs, []string{"extract", "transform"}) {
+		t.Errorf("unexpected disabled jobs %v", plan.Options.DisabledJobs)
+	}
+
+	resp, err := client.RetryFailedJobs(failed.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry := s.Execution(resp.ExecutionID)
+	if retry == nil || retry.Job("load").Status != "READY" || retry.Job("extract").Status != "DISABLED" {
+		t.Errorf("unexpected retry execution %v", retry)
+	}
+
+	// The failure action is kept, and jobs that succeeded in embedded flows are disabled on their own
+	nested := s.AddExecution("example", "daily", "FAILED", time.Now())
+	nested.Options.FailureAction = azkaban.FinishPossible
+	load := nested.Job("load")
+	load.Type, load.Flow = "flow", "load_flow"
+	load.Jobs = []*azkabantest.JobExecution{
+		{ID: "prepare", Type: "command", Status: "SUCCEEDED", StartTime: load.StartTime, EndTime: load.EndTime},
+		{ID: "write", Type: "command", In: []string{"prepare"}, Status: "FAILED", StartTime: load.StartTime, EndTime: load.EndTime},
+	}
+	plan, err = client.PlanRetry(nested.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(plan.Options.DisabledJobs, []string{"extract", "load:prepare", "transform"}) {
+		t.Errorf("unexpected disabled jobs %v", plan.Options.DisabledJobs)
+	}
+	if plan.Options.FailureAction != azkaban.FinishPossible {
+		t.Errorf("expected failure action %q, got %q", azkaban.FinishPossible, plan.Options.FailureAction)
+	}
+	resp, err = client.RetryFailedJobs(nested.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retry = s.Execution(resp.ExecutionID)
+	sort.Strings(retry.Options.DisabledJobs)
+	if retry.Options.FailureAction != azkaban.FinishPossible || !reflect.DeepEqual(retry.Options.DisabledJobs, plan.Options.DisabledJobs) {
+		t.Errorf("unexpected retry options %v", retry.Options)
+	}
+
+	succeeded := s.AddExecution("example", "daily", "SUCCEEDED", time.Now())
+	if _, err := client.PlanRetry(succeeded.ID); err == nil {
+		t.Error("expected error retrying a succeeded execution")
+	}
+}
+
+func TestSchedules(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	schedule, err := client.FlowSchedule(1, "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.IsScheduled() {
+		t.Errorf("expected flow not to be scheduled, got %v", schedule)
+	}
+
+	resp, err := client.ScheduleCronFlow("example", "daily", "0 0 2 ? * MON-FRI", azkaban.ExecutionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ScheduleID == 0 {
+		t.Errorf("expected schedule ID, got %v", resp)
+	}
+
+	schedule, err = client.FlowSchedule(1, "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schedule.IsScheduled() || schedule.CronExpression.String() != "0 0 2 ? * MON-FRI" {
+		t.Errorf("unexpected schedule %v", schedule)
+	}
+	if next := schedule.NextExecTime.Time().In(azkaban.ServerLocation); next.Hour() != 2 || next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		t.Errorf("unexpected next execution %s", next)
+	}
+
+	schedules, err := client.Schedules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schedules) != 1 || schedules[0].FlowID != "daily" || schedules[0].Project != "example" {
+		t.Errorf("unexpected schedules %v", schedules)
+	}
+
+	if err := client.RemoveSchedule(schedule.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Schedules()) != 0 {
+		t.Errorf("expected schedule to be removed, got %v", s.Schedules())
+	}
+	if err := client.RemoveSchedule(schedule.ID); err == nil {
+		t.Error("expected error removing a missing schedule")
+	}
+
+	if _, err := client.ScheduleCronFlow("example", "missing", "0 0 2 ? * *", azkaban.ExecutionOptions{}); err == nil {
+		t.Error("expected error scheduling a missing flow")
+	}
+}
+
+func TestLogin(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+
+	client, err := azkaban.ConnectWithUsernameAndPassword(s.URL, azkabantest.DefaultUsername, azkabantest.DefaultPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.SessionID == "" || client.SessionID == azkabantest.DefaultSessionID {
+		t.Errorf("expected a new session, got %q", client.SessionID)
+	}
+	if _, err := client.ListProjects(); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := azkaban.ConnectWithUsernameAndPassword(s.URL, "azkaban", "wrong"); !azkaban.IsPermissionDenied(err) {
+		t.Errorf("expected permission denied for wrong password, got %v", err)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+	s.ExpireSessions()
+
+	if _, err := client.ListProjects(); err != azkaban.ErrInvalidSessionID {
+		t.Errorf("expected %v, got %v", azkaban.ErrInvalidSessionID, err)
+	}
+	if _, err := client.Running(); err != azkaban.ErrInvalidSessionID {
+		t.Errorf("expected %v without falling back to the executor page, got %v", azkaban.ErrInvalidSessionID, err)
+	}
+
+	var renewed string
+	client.Credentials = azkaban.StaticCredentials(azkabantest.DefaultUsername, azkabantest.DefaultPassword)
+	client.OnSessionRenewed = func(sessionID string) { renewed = sessionID }
+	if _, err := client.ListProjects(); err != nil {
+		t.Fatal(err)
+	}
+	if renewed == "" || renewed != client.SessionID {
+		t.Errorf("expected renewed session to be reported, got %q", renewed)
+	}
+}
+
+func TestContextCancellation(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	client := s.Client()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ListProjectsContext(ctx); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+	if _, err := client.RunningContext(ctx); err == nil {
+		t.Error("expected error for cancelled context")
+	}
+
+	// A request waiting for the rate limit gives up once the context is done
+	client.SetRateLimit(0.1)
+	if _, err := client.ListProjects(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := client.ListProjectsContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if waited := time.Since(start); waited > time.Second {
+		t.Errorf("expected to give up waiting for the rate limit, waited %s", waited)
+	}
+}
+
+func TestRepositories(t *testing.T) {
+	s := newServer(t)
+	defer s.Close()
+	s.AddExecution("example", "daily", "SUCCEEDED", time.Now())
+	ctx := azkaban.NewContext(s.Client())
+
+	project, err := ctx.Projects().ByName("example")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flow, _, err := ctx.Flows().Flow(project, "daily")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flows, err := ctx.Flows().ListFlows(project, azkaban.MatchesFlowName("dai"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flows) != 1 {
+		t.Errorf("unexpected flows %v", flows)
+	}
+	executions, err := ctx.Executions().ListExecutions(project, flow, azkaban.TenMostRecent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(executions) != 1 || !strings.EqualFold(string(executions[0].Status), "succeeded") {
+		t.Errorf("unexpected executions %v", executions)
+	}
+}
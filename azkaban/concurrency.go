@@ -0,0 +1,53 @@
+package azkaban
+
+import (
+	"sync"
+)
+
+// ForEachConcurrently calls f for every i from 0 to n-1 on at most concurrency goroutines, e.g. to send requests to
+// Azkaban in parallel. Callers keep results in stable order by storing them at index i. Returns the error of the lowest
+// i that failed; once a call failed no new calls are started. A concurrency below 1 is treated as 1.
+func ForEachConcurrently(n int, concurrency int, f func(i int) error) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	indexes := make(chan int)
+	errs := make([]error, n)
+	var failed bool
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if err := f(i); err != nil {
+					mutex.Lock()
+					errs[i] = err
+					failed = true
+					mutex.Unlock()
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		mutex.Lock()
+		stop := failed
+		mutex.Unlock()
+		if stop {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
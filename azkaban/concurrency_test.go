@@ -0,0 +1,91 @@
+package azkaban
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestForEachConcurrently(t *testing.T) {
+	var mutex sync.Mutex
+	running, maxRunning := 0, 0
+	results := make([]int, 20)
+
+	err := ForEachConcurrently(len(results), 3, func(i int) error {
+		mutex.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mutex.Unlock()
+
+		// Finish in reverse order to check results stay in place
+		time.Sleep(time.Duration(len(results)-i) * time.Millisecond)
+		results[i] = i * i
+
+		mutex.Lock()
+		running--
+		mutex.Unlock()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxRunning > 3 {
+		t.Errorf("expected at most 3 concurrent calls, got %d", maxRunning)
+	}
+	for i, r := range results {
+		if r != i*i {
+			t.Errorf("expected %d at %d, got %d", i*i, i, r)
+		}
+	}
+}
+
+func TestForEachConcurrentlyStopsOnError(t *testing.T) {
+	var mutex sync.Mutex
+	calls := 0
+	expected := errors.New("failed")
+
+	err := ForEachConcurrently(100, 2, func(i int) error {
+		mutex.Lock()
+		calls++
+		mutex.Unlock()
+		if i == 3 {
+			return expected
+		}
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	if err != expected {
+		t.Errorf("expected %v, got %v", expected, err)
+	}
+	if calls == 100 {
+		t.Error("expected no new calls after an error")
+	}
+}
+
+func TestForEachConcurrentlyWithoutConcurrency(t *testing.T) {
+	for _, concurrency := range []int{0, -1} {
+		calls := 0
+		done := make(chan error)
+		go func() {
+			done <- ForEachConcurrently(5, concurrency, func(i int) error {
+				calls++
+				return nil
+			})
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Errorf("concurrency %d: %s", concurrency, err)
+			}
+			if calls != 5 {
+				t.Errorf("concurrency %d: expected 5 calls, got %d", concurrency, calls)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("concurrency %d: ForEachConcurrently didn't return", concurrency)
+		}
+	}
+}
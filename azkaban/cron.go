@@ -0,0 +1,374 @@
+package azkaban
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	cronSeconds = iota
+	cronMinutes
+	cronHours
+	cronDayOfMonth
+	cronMonth
+	cronDayOfWeek
+	cronYear
+)
+
+type cronField struct {
+	name  string
+	min   int
+	max   int
+	names []string
+}
+
+var cronFields = []cronField{
+	{name: "seconds", min: 0, max: 59},
+	{name: "minutes", min: 0, max: 59},
+	{name: "hours", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}},
+	{name: "day of week", min: 1, max: 7, names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}},
+	{name: "year", min: 1970, max: 2099},
+}
+
+// CronExpression is a parsed Quartz cron expression as used by Azkaban for cron schedules. Quartz expressions have six
+// or seven fields: seconds, minutes, hours, day of month, month, day of week, and an optional year. Days of week are
+// numbered 1 (Sunday) to 7 (Saturday). Exactly one of day of month and day of week must be "?".
+type CronExpression struct {
+	expression string
+	// sets hold the allowed values for each field, indexed by value
+	sets [7][]bool
+
+	// day of month specials: L, L-n, LW, nW
+	lastDayOfMonth       bool
+	lastDayOffset        int
+	nearestWeekday       int
+	lastWeekdayOfMonth   bool
+	dayOfMonthIgnored    bool
+	lastDayOfWeekInMonth int // nL, 0 if unused
+	nthDayOfWeek         int // n#k, day of week, 0 if unused
+	nthDayOfWeekOrdinal  int // n#k, k
+
+	// err is why the expression couldn't be parsed when it was decoded from JSON
+	err error
+}
+
+// ParseCronExpression parses a Quartz cron expression like "0 0 2 ? * MON-FRI".
+func ParseCronExpression(expression string) (CronExpression, error) {
+	c := CronExpression{expression: expression}
+
+	fields := strings.Fields(strings.ToUpper(expression))
+	if len(fields) != 6 && len(fields) != 7 {
+		return c, fmt.Errorf("cron expression %q has %d fields, expected 6 or 7", expression, len(fields))
+	}
+	if len(fields) == 6 {
+		fields = append(fields, "*")
+	}
+
+	if (fields[cronDayOfMonth] == "?") == (fields[cronDayOfWeek] == "?") {
+		return c, fmt.Errorf("cron expression %q must specify exactly one of day of month and day of week as ?", expression)
+	}
+	c.dayOfMonthIgnored = fields[cronDayOfMonth] == "?"
+
+	for i, field := range fields {
+		set := make([]bool, cronFields[i].max+1)
+		c.sets[i] = set
+
+		if field == "?" {
+			continue
+		}
+
+		var err error
+		switch {
+		case i == cronDayOfMonth && strings.ContainsAny(field, "LW"):
+			err = c.parseDayOfMonthSpecial(field)
+		case i == cronDayOfWeek && strings.ContainsAny(field, "L#"):
+			err = c.parseDayOfWeekSpecial(field)
+		default:
+			err = parseCronField(field, cronFields[i], set)
+		}
+		if err != nil {
+			return c, fmt.Errorf("cron expression %q: %s", expression, err)
+		}
+	}
+
+	return c, nil
+}
+
+func parseCronField(field string, f cronField, set []bool) error {
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				return fmt.Errorf("invalid step %q in %s", part[i+1:], f.name)
+			}
+			part = part[:i]
+		}
+
+		var from, to int
+		switch {
+		case part == "*":
+			from, to = f.min, f.max
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if from, err = parseCronValue(bounds[0], f); err != nil {
+				return err
+			}
+			if to, err = parseCronValue(bounds[1], f); err != nil {
+				return err
+			}
+		default:
+			var err error
+			if from, err = parseCronValue(part, f); err != nil {
+				return err
+			}
+			to = from
+			// "5/15" means starting at 5 every 15
+			if step > 1 {
+				to = f.max
+			}
+		}
+
+		// Ranges may wrap around, e.g. 22-2 for hours or FRI-MON for days of week
+		span := to - from
+		if span < 0 {
+			span += f.max - f.min + 1
+		}
+		for offset := 0; offset <= span; offset += step {
+			v := from + offset
+			if v > f.max {
+				v -= f.max - f.min + 1
+			}
+			set[v] = true
+		}
+	}
+
+	return nil
+}
+
+func parseCronValue(s string, f cronField) (int, error) {
+	for i, name := range f.names {
+		if s == name {
+			return f.min + i, nil
+		}
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s", s, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d in %s", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+func (c *CronExpression) parseDayOfMonthSpecial(field string) error {
+	f := cronFields[cronDayOfMonth]
+	switch {
+	case field == "L":
+		c.lastDayOfMonth = true
+	case field == "LW":
+		c.lastWeekdayOfMonth = true
+	case strings.HasPrefix(field, "L-"):
+		offset, err := strconv.Atoi(field[2:])
+		if err != nil || offset < 0 || offset > 30 {
+			return fmt.Errorf("invalid offset in %q in %s", field, f.name)
+		}
+		c.lastDayOfMonth = true
+		c.lastDayOffset = offset
+	case strings.HasSuffix(field, "W"):
+		day, err := parseCronValue(strings.TrimSuffix(field, "W"), f)
+		if err != nil {
+			return err
+		}
+		c.nearestWeekday = day
+	default:
+		return fmt.Errorf("invalid value %q in %s", field, f.name)
+	}
+	return nil
+}
+
+func (c *CronExpression) parseDayOfWeekSpecial(field string) error {
+	f := cronFields[cronDayOfWeek]
+	switch {
+	case field == "L":
+		// A plain L in day of week means Saturday
+		c.sets[cronDayOfWeek][7] = true
+	case strings.HasSuffix(field, "L"):
+		day, err := parseCronValue(strings.TrimSuffix(field, "L"), f)
+		if err != nil {
+			return err
+		}
+		c.lastDayOfWeekInMonth = day
+	case strings.Contains(field, "#"):
+		parts := strings.SplitN(field, "#", 2)
+		day, err := parseCronValue(parts[0], f)
+		if err != nil {
+			return err
+		}
+		ordinal, err := strconv.Atoi(parts[1])
+		if err != nil || ordinal < 1 || ordinal > 5 {
+			return fmt.Errorf("invalid ordinal in %q in %s", field, f.name)
+		}
+		c.nthDayOfWeek = day
+		c.nthDayOfWeekOrdinal = ordinal
+	default:
+		return fmt.Errorf("invalid value %q in %s", field, f.name)
+	}
+	return nil
+}
+
+// String returns the expression as it was parsed.
+func (c CronExpression) String() string {
+	return c.expression
+}
+
+// IsZero returns true if this is the zero value, i.e. no expression was parsed.
+func (c CronExpression) IsZero() bool {
+	return c.expression == ""
+}
+
+// Err returns why the expression couldn't be parsed, or nil if it could. Only expressions decoded from JSON can have an
+// error; they keep the raw expression but never fire.
+func (c CronExpression) Err() error {
+	return c.err
+}
+
+// UnmarshalJSON parses the expression. Expressions Azkaban accepts but ParseCronExpression doesn't are kept rather than
+// failing the decode, see Err.
+func (c *CronExpression) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == nil || *s == "" {
+		*c = CronExpression{}
+		return nil
+	}
+
+	parsed, err := ParseCronExpression(*s)
+	if err != nil {
+		*c = CronExpression{expression: *s, err: err}
+		return nil
+	}
+	*c = parsed
+	return nil
+}
+
+func (c CronExpression) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.expression)
+}
+
+// Next returns the first fire time after the given time, evaluated in the location of the given time. Returns the zero
+// time if the expression never fires again or couldn't be parsed.
+func (c CronExpression) Next(after time.Time) time.Time {
+	if c.IsZero() || c.err != nil {
+		return time.Time{}
+	}
+
+	loc := after.Location()
+	start := after.Truncate(time.Second).Add(time.Second)
+	y, m, d := start.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, loc)
+
+	for day.Year() <= cronFields[cronYear].max {
+		y, m, d := day.Date()
+		if !c.sets[cronYear][y] {
+			day = time.Date(y+1, time.January, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.sets[cronMonth][int(m)] {
+			day = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.matchesDay(y, m, d, day.Weekday()) {
+			if t, ok := c.nextTimeOfDay(y, m, d, loc, start); ok {
+				return t
+			}
+		}
+		day = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+	}
+
+	return time.Time{}
+}
+
+func (c CronExpression) nextTimeOfDay(y int, m time.Month, d int, loc *time.Location, notBefore time.Time) (time.Time, bool) {
+	for h, hourSet := range c.sets[cronHours] {
+		if !hourSet {
+			continue
+		}
+		for min, minuteSet := range c.sets[cronMinutes] {
+			if !minuteSet {
+				continue
+			}
+			for s, secondSet := range c.sets[cronSeconds] {
+				if !secondSet {
+					continue
+				}
+				t := time.Date(y, m, d, h, min, s, 0, loc)
+				if !t.Before(notBefore) {
+					return t, true
+				}
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+func (c CronExpression) matchesDay(y int, m time.Month, d int, weekday time.Weekday) bool {
+	lastDay := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	if !c.dayOfMonthIgnored {
+		switch {
+		case c.lastDayOfMonth:
+			return d == lastDay-c.lastDayOffset
+		case c.lastWeekdayOfMonth:
+			return d == nearestWeekday(y, m, lastDay)
+		case c.nearestWeekday > 0:
+			return d == nearestWeekday(y, m, c.nearestWeekday)
+		default:
+			return c.sets[cronDayOfMonth][d]
+		}
+	}
+
+	// Quartz numbers days of week 1 (Sunday) to 7 (Saturday)
+	dayOfWeek := int(weekday) + 1
+	switch {
+	case c.lastDayOfWeekInMonth > 0:
+		return dayOfWeek == c.lastDayOfWeekInMonth && d+7 > lastDay
+	case c.nthDayOfWeek > 0:
+		return dayOfWeek == c.nthDayOfWeek && (d-1)/7+1 == c.nthDayOfWeekOrdinal
+	default:
+		return c.sets[cronDayOfWeek][dayOfWeek]
+	}
+}
+
+// nearestWeekday returns the weekday (Monday to Friday) closest to the given day that is in the same month, or -1 if
+// the month doesn't have that day.
+func nearestWeekday(y int, m time.Month, day int) int {
+	lastDay := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if day > lastDay {
+		return -1
+	}
+	switch time.Date(y, m, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return day + 2
+		}
+		return day - 1
+	case time.Sunday:
+		if day == lastDay {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
@@ -0,0 +1,196 @@
+package azkaban
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCronExpressionNext(t *testing.T) {
+	// Friday
+	from := time.Date(2019, time.August, 23, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		expression string
+		expected   []time.Time
+	}{
+		{
+			expression: "0 0 2 ? * MON-FRI",
+			expected: []time.Time{
+				time.Date(2019, time.August, 26, 2, 0, 0, 0, time.UTC),
+				time.Date(2019, time.August, 27, 2, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 */15 * * * ?",
+			expected: []time.Time{
+				time.Date(2019, time.August, 23, 10, 45, 0, 0, time.UTC),
+				time.Date(2019, time.August, 23, 11, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 0 12 L * ?",
+			expected: []time.Time{
+				time.Date(2019, time.August, 31, 12, 0, 0, 0, time.UTC),
+				time.Date(2019, time.September, 30, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 0 12 LW * ?",
+			expected: []time.Time{
+				time.Date(2019, time.August, 30, 12, 0, 0, 0, time.UTC),
+				time.Date(2019, time.September, 30, 12, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			// 1st of September 2019 is a Sunday
+			expression: "0 0 8 1W * ?",
+			expected: []time.Time{
+				time.Date(2019, time.September, 2, 8, 0, 0, 0, time.UTC),
+				time.Date(2019, time.October, 1, 8, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 0 9 ? * 6#2",
+			expected: []time.Time{
+				time.Date(2019, time.September, 13, 9, 0, 0, 0, time.UTC),
+				time.Date(2019, time.October, 11, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 0 9 ? * FRIL",
+			expected: []time.Time{
+				time.Date(2019, time.August, 30, 9, 0, 0, 0, time.UTC),
+				time.Date(2019, time.September, 27, 9, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 30 22-1 ? * SAT",
+			expected: []time.Time{
+				time.Date(2019, time.August, 24, 0, 30, 0, 0, time.UTC),
+				time.Date(2019, time.August, 24, 1, 30, 0, 0, time.UTC),
+				time.Date(2019, time.August, 24, 22, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 0 0 29 FEB ? 2020-2030",
+			expected: []time.Time{
+				time.Date(2020, time.February, 29, 0, 0, 0, 0, time.UTC),
+				time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			expression: "0 0 0 1 JAN ? 2018",
+			expected:   nil,
+		},
+	}
+
+	for _, test := range tests {
+		c, err := ParseCronExpression(test.expression)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", test.expression, err)
+			continue
+		}
+
+		actual := NextN(c, from, len(test.expected)+1)
+		if len(actual) < len(test.expected) {
+			t.Errorf("%q: expected %v but got %v", test.expression, test.expected, actual)
+			continue
+		}
+		for i, expected := range test.expected {
+			if !actual[i].Equal(expected) {
+				t.Errorf("%q: expected fire time %d to be %s but got %s", test.expression, i, expected, actual[i])
+			}
+		}
+	}
+}
+
+func TestParseCronExpressionErrors(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"0 0 2 * *",
+		"0 0 2 * * *",
+		"0 0 2 ? * ?",
+		"0 60 2 ? * *",
+		"0 0 2 ? FOO *",
+		"0 0 2 ? * 8",
+		"0 0 2 ? * MON#6",
+		"0 0 */0 * * ?",
+	} {
+		if _, err := ParseCronExpression(expression); err == nil {
+			t.Errorf("expected error parsing %q", expression)
+		}
+	}
+}
+
+func TestFlowScheduleWithUnsupportedCronExpression(t *testing.T) {
+	var schedule FlowSchedule
+	err := json.Unmarshal([]byte(`{
+		"scheduleId": "7",
+		"submitUser": "azkaban",
+		"nextExecTime": "2017-08-01 02:00:00",
+		"period": "null",
+		"cronExpression": "0 0 2 ? * MON#6"
+	}`), &schedule)
+	if err != nil {
+		t.Fatalf("expected schedule to decode but got %s", err)
+	}
+	if !schedule.IsScheduled() {
+		t.Error("expected flow to be scheduled")
+	}
+	if schedule.CronExpression.Err() == nil {
+		t.Error("expected cron expression to have an error")
+	}
+	if actual := schedule.Description(); actual != "0 0 2 ? * MON#6" {
+		t.Errorf("expected raw cron expression as description but got %q", actual)
+	}
+	if actual := schedule.NextExecutions(3); len(actual) != 0 {
+		t.Errorf("expected no upcoming executions but got %v", actual)
+	}
+}
+
+func TestParseSchedulePeriod(t *testing.T) {
+	tests := map[string]SchedulePeriod{
+		"null":          {},
+		"1 day(s)":      {Count: 1, Unit: PeriodDays},
+		"12 hour(s)":    {Count: 12, Unit: PeriodHours},
+		"2 week(s)":     {Count: 2, Unit: PeriodWeeks},
+		"30 minutes":    {Count: 30, Unit: PeriodMinutes},
+		"1 month(s)":    {Count: 1, Unit: PeriodMonths},
+		"Not Recurring": {},
+	}
+
+	for input, expected := range tests {
+		period, err := ParseSchedulePeriod(input)
+		if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", input, err)
+		}
+		if period != expected {
+			t.Errorf("expected %v for %q but got %v", expected, input, period)
+		}
+	}
+
+	if _, err := ParseSchedulePeriod("1 fortnight(s)"); err == nil {
+		t.Error("expected error for unknown unit")
+	}
+}
+
+func TestPeriodicScheduleNext(t *testing.T) {
+	start := time.Date(2019, time.January, 31, 3, 0, 0, 0, time.UTC)
+	schedule := PeriodicSchedule{Start: start, Period: SchedulePeriod{Count: 1, Unit: PeriodDays}}
+
+	next := schedule.Next(time.Date(2019, time.August, 23, 10, 30, 0, 0, time.UTC))
+	expected := time.Date(2019, time.August, 24, 3, 0, 0, 0, time.UTC)
+	if !next.Equal(expected) {
+		t.Errorf("expected %s but got %s", expected, next)
+	}
+
+	if next := schedule.Next(start.Add(-time.Hour)); !next.Equal(start) {
+		t.Errorf("expected start %s but got %s", start, next)
+	}
+
+	notRecurring := PeriodicSchedule{Start: start}
+	if next := notRecurring.Next(start); !next.IsZero() {
+		t.Errorf("expected no next execution but got %s", next)
+	}
+}
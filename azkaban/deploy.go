@@ -0,0 +1,283 @@
+package azkaban
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CreateProject creates a new, empty project.
+func (c *Client) CreateProject(name, description string) error {
+	return c.CreateProjectContext(context.Background(), name, description)
+}
+
+// CreateProjectContext is like CreateProject but sends its requests with the given context.
+func (c *Client) CreateProjectContext(ctx context.Context, name, description string) error {
+	params := make(map[string]string)
+	params["action"] = "create"
+	params["name"] = name
+	params["description"] = description
+
+	result := CreateProjectResponse{}
+	return c.requestAndDecode(ctx, "POST", "manager", params, &result)
+}
+
+// DeleteProject deletes the given project with all its flows, executions, and schedules.
+func (c *Client) DeleteProject(name string) error {
+	return c.DeleteProjectContext(context.Background(), name)
+}
+
+// DeleteProjectContext is like DeleteProject but sends its requests with the given context.
+func (c *Client) DeleteProjectContext(ctx context.Context, name string) error {
+	// Azkaban answers the delete with a redirect to a html page whether it deleted the project or not, so make sure the
+	// project exists before and is gone after.
+	if _, err := c.ListFlowsContext(ctx, name); err != nil {
+		return err
+	}
+
+	params := make(map[string]string)
+	params["project"] = name
+	params["delete"] = "true"
+	resp, err := c.request(ctx, "GET", "manager", params)
+	if err != nil {
+		return err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError("GET", "manager", params, resp.StatusCode, "")
+	}
+
+	_, err = c.ListFlowsContext(ctx, name)
+	if err == nil {
+		return &APIError{
+			Endpoint:   "manager?delete=true",
+			Method:     "GET",
+			StatusCode: resp.StatusCode,
+			Message:    fmt.Sprintf("project %s was not deleted, you might lack the permission to delete it", name),
+			Err:        ErrPermissionDenied,
+		}
+	}
+	if !IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// UploadProjectZip uploads the given zip file as a new version of the given project. The response has the new version
+// and Azkaban's validation warnings, if any.
+func (c *Client) UploadProjectZip(project string, zip io.Reader) (UploadProjectResponse, error) {
+	return c.UploadProjectZipContext(context.Background(), project, zip)
+}
+
+// UploadProjectZipContext is like UploadProjectZip but sends its requests with the given context.
+func (c *Client) UploadProjectZipContext(ctx context.Context, project string, zip io.Reader) (UploadProjectResponse, error) {
+	result := UploadProjectResponse{}
+	// Keep the zip in memory so the upload can be repeated after renewing the session
+	content, err := ioutil.ReadAll(zip)
+	if err != nil {
+		return result, err
+	}
+
+	params := make(map[string]string)
+	params["ajax"] = "upload"
+	params["project"] = project
+
+	err = c.withSession(ctx, func() error {
+		result = UploadProjectResponse{}
+		body, contentType, err := c.multipartBody(params, "file", project+".zip", content)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequest("POST", c.url+"manager", body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", contentType)
+
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		return decodeResponse("POST", "manager", params, resp, &result)
+	})
+	return result, err
+}
+
+// ProjectLogs returns the event log of the given project, most recent first.
+func (c *Client) ProjectLogs(project string) ([]ProjectEvent, error) {
+	return c.ProjectLogsContext(context.Background(), project)
+}
+
+// ProjectLogsContext is like ProjectLogs but sends its requests with the given context.
+func (c *Client) ProjectLogsContext(ctx context.Context, project string) ([]ProjectEvent, error) {
+	// Azkaban returns at most size events per request, so page through the log until it is exhausted
+	var events []ProjectEvent
+	for skip := 0; ; skip += projectLogPageSize {
+		params := make(map[string]string)
+		params["ajax"] = "fetchProjectLogs"
+		params["project"] = project
+		params["size"] = strconv.Itoa(projectLogPageSize)
+		params["skip"] = strconv.Itoa(skip)
+
+		result := ProjectLogsResponse{}
+		if err := c.requestAndDecode(ctx, "GET", "manager", params, &result); err != nil {
+			return nil, err
+		}
+		page, err := result.Events()
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, page...)
+		if len(page) < projectLogPageSize {
+			return events, nil
+		}
+	}
+}
+
+// projectLogPageSize is how many events ProjectLogs asks for per request, Azkaban's default
+const projectLogPageSize = 1000
+
+// ProjectVersion is an uploaded version of a project.
+type ProjectVersion struct {
+	Version    int
+	UploadUser string
+	UploadTime time.Time
+	// Message is Azkaban's log message of the upload which names the uploaded file
+	Message string
+}
+
+// ProjectVersions returns the uploaded versions of the given project, most recent first. Azkaban doesn't log version
+// numbers, every upload creates the next version so they are counted from the uploads in the project's log. That is
+// best-effort: if the log is incomplete, e.g. because Azkaban trimmed it, the numbers are off. Don't derive the latest
+// version from them, DownloadProject with version 0 gets it from Azkaban.
+func (c *Client) ProjectVersions(project string) ([]ProjectVersion, error) {
+	return c.ProjectVersionsContext(context.Background(), project)
+}
+
+// ProjectVersionsContext is like ProjectVersions but sends its requests with the given context.
+func (c *Client) ProjectVersionsContext(ctx context.Context, project string) ([]ProjectVersion, error) {
+	events, err := c.ProjectLogsContext(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	var uploads []ProjectEvent
+	for _, e := range events {
+		if e.Type == "UPLOADED" {
+			uploads = append(uploads, e)
+		}
+	}
+	versions := []ProjectVersion{}
+	for i, e := range uploads {
+		versions = append(versions, ProjectVersion{
+			Version:    len(uploads) - i,
+			UploadUser: e.User,
+			UploadTime: e.Time,
+			Message:    e.Message,
+		})
+	}
+	return versions, nil
+}
+
+// ProjectZip is a project zip file downloaded from Azkaban.
+type ProjectZip struct {
+	// FileName is the name Azkaban served the zip with, the name of the uploaded file. Empty if Azkaban didn't send one.
+	FileName string
+	Content  []byte
+}
+
+// DownloadProject returns the zip file of the given version of the project, or of the latest version if version is 0.
+func (c *Client) DownloadProject(project string, version int) (ProjectZip, error) {
+	return c.DownloadProjectContext(context.Background(), project, version)
+}
+
+// DownloadProjectContext is like DownloadProject but sends its requests with the given context.
+func (c *Client) DownloadProjectContext(ctx context.Context, project string, version int) (ProjectZip, error) {
+	var zip ProjectZip
+	// Azkaban answers downloads of missing projects or versions, and with expired sessions, with a redirect to a html
+	// page, so make sure the session is valid and the project exists first.
+	if _, err := c.ListFlowsContext(ctx, project); err != nil {
+		return zip, err
+	}
+
+	params := make(map[string]string)
+	params["project"] = project
+	params["download"] = "true"
+	if version > 0 {
+		params["version"] = strconv.Itoa(version)
+	}
+	resp, err := c.request(ctx, "GET", "manager", params)
+	if err != nil {
+		return zip, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return zip, newAPIError("GET", "manager", params, resp.StatusCode, "")
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/zip") {
+		io.Copy(ioutil.Discard, resp.Body)
+		message := fmt.Sprintf("project %s has no version %d", project, version)
+		if version == 0 {
+			message = fmt.Sprintf("project %s has no uploaded version", project)
+		}
+		return zip, &APIError{
+			Endpoint:   "manager?download=true",
+			Method:     "GET",
+			StatusCode: resp.StatusCode,
+			Message:    message,
+			Err:        ErrNotFound,
+		}
+	}
+
+	if _, disposition, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := path.Base(strings.Replace(disposition["filename"], "\\", "/", -1)); name != "." && name != "/" {
+			zip.FileName = name
+		}
+	}
+	zip.Content, err = ioutil.ReadAll(resp.Body)
+	return zip, err
+}
+
+// multipartBody builds a multipart form with the session ID, the given fields, and the given zip file.
+func (c *Client) multipartBody(fields map[string]string, fileField string, fileName string, content []byte) (io.Reader, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("session.id", c.currentSessionID()); err != nil {
+		return nil, "", err
+	}
+	for k, v := range fields {
+		if err := w.WriteField(k, v); err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Azkaban rejects files that are not sent as application/zip
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, fileField, fileName))
+	header.Set("Content-Type", "application/zip")
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return nil, "", err
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return &body, w.FormDataContentType(), nil
+}
@@ -0,0 +1,99 @@
+package azkaban
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrNotFound is the cause of APIErrors about projects, flows, executions, jobs, or schedules that don't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrPermissionDenied is the cause of APIErrors about missing permissions, and of failed logins.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrSessionExpired is returned as is, not wrapped in an APIError, when Azkaban rejects the session ID.
+	ErrSessionExpired = errors.New("invalid session id, session might have expired")
+	// ErrInvalidSessionID is the old name of ErrSessionExpired.
+	ErrInvalidSessionID = ErrSessionExpired
+)
+
+// APIError is an error reported by Azkaban, either as an error in its response or as a HTTP status other than 200.
+type APIError struct {
+	// Endpoint is the path and ajax action of the request, e.g. "manager?ajax=fetchprojectflows"
+	Endpoint   string
+	Method     string
+	StatusCode int
+	// Message is Azkaban's error text, or the HTTP status if there is none
+	Message string
+	// Err is ErrNotFound or ErrPermissionDenied if the error is one of those, nil otherwise
+	Err error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %s", e.Method, e.Endpoint, e.Message)
+}
+
+// Unwrap returns the cause of the error, see Err.
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound returns true if err is an APIError caused by a missing project, flow, execution, job, or schedule.
+func IsNotFound(err error) bool {
+	return causeOf(err) == ErrNotFound
+}
+
+// IsPermissionDenied returns true if err is an APIError caused by missing permissions or a failed login.
+func IsPermissionDenied(err error) bool {
+	return causeOf(err) == ErrPermissionDenied
+}
+
+func causeOf(err error) error {
+	if apiErr, ok := err.(*APIError); ok {
+		return apiErr.Err
+	}
+	return err
+}
+
+// newAPIError builds the error for the given Azkaban error text, or for the HTTP status if the text is empty. Returns
+// ErrSessionExpired instead if Azkaban rejected the session.
+func newAPIError(method string, path string, params map[string]string, statusCode int, message string) error {
+	if message == "session" {
+		return ErrSessionExpired
+	}
+
+	endpoint := path
+	if action, ok := params["ajax"]; ok {
+		endpoint = fmt.Sprintf("%s?ajax=%s", path, action)
+	} else if action, ok := params["action"]; ok {
+		endpoint = fmt.Sprintf("%s?action=%s", path, action)
+	}
+	if message == "" {
+		message = fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode))
+	}
+
+	return &APIError{
+		Endpoint:   endpoint,
+		Method:     method,
+		StatusCode: statusCode,
+		Message:    message,
+		Err:        classifyError(statusCode, message),
+	}
+}
+
+// classifyError guesses the cause of an Azkaban error. Azkaban answers most errors with HTTP 200 and an error text, so
+// this looks at the wording of the text, e.g. "Project foo doesn't exist." or "Cannot find execution '123'".
+func classifyError(statusCode int, message string) error {
+	m := strings.ToLower(message)
+	switch {
+	case statusCode == http.StatusForbidden || statusCode == http.StatusUnauthorized,
+		strings.Contains(m, "permission"), strings.Contains(m, "access denied"):
+		return ErrPermissionDenied
+	case statusCode == http.StatusNotFound,
+		strings.Contains(m, "doesn't exist"), strings.Contains(m, "does not exist"),
+		strings.Contains(m, "not found"), strings.Contains(m, "cannot be found"), strings.Contains(m, "cannot find"):
+		return ErrNotFound
+	}
+	return nil
+}
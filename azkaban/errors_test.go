@@ -0,0 +1,30 @@
+package azkaban
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		message    string
+		expected   error
+	}{
+		{http.StatusOK, "Project foo doesn't exist.", ErrNotFound},
+		{http.StatusOK, "Cannot find execution '123'", ErrNotFound},
+		{http.StatusOK, "Flow daily cannot be found in project foo", ErrNotFound},
+		{http.StatusOK, "Schedule with ID 1 does not exist", ErrNotFound},
+		{http.StatusOK, "Permission denied. Need READ access.", ErrPermissionDenied},
+		{http.StatusForbidden, "", ErrPermissionDenied},
+		{http.StatusNotFound, "", ErrNotFound},
+		{http.StatusOK, "Execution 1 is not running.", nil},
+		{http.StatusInternalServerError, "", nil},
+	}
+
+	for _, test := range tests {
+		if err := classifyError(test.statusCode, test.message); err != test.expected {
+			t.Errorf("%d %q: expected %v, got %v", test.statusCode, test.message, test.expected, err)
+		}
+	}
+}
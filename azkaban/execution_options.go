@@ -0,0 +1,165 @@
+package azkaban
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FailureAction determines what Azkaban does with the rest of a flow once a job failed.
+type FailureAction string
+
+const (
+	// FinishCurrent lets currently running jobs finish but doesn't start new ones
+	FinishCurrent FailureAction = "finishCurrent"
+	// CancelImmediately kills all running jobs and fails the flow
+	CancelImmediately FailureAction = "cancelImmediately"
+	// FinishPossible keeps executing all jobs whose dependencies are still met
+	FinishPossible FailureAction = "finishPossible"
+)
+
+// ParseFailureAction parses both Azkaban's names (finishCurrent) and their dashed forms (finish-current), as well as the
+// enum names Azkaban reports for executions (FINISH_CURRENTLY_RUNNING).
+func ParseFailureAction(s string) (FailureAction, error) {
+	switch strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(s)) {
+	case "finishcurrent", "finishcurrentlyrunning":
+		return FinishCurrent, nil
+	case "cancelimmediately", "cancelall":
+		return CancelImmediately, nil
+	case "finishpossible", "finishallpossible":
+		return FinishPossible, nil
+	}
+	return "", fmt.Errorf("unknown failure action %q, valid are finish-current, cancel-immediately, finish-possible", s)
+}
+
+// ConcurrentOption determines what Azkaban does if the flow is already running when a new execution is submitted.
+type ConcurrentOption string
+
+const (
+	// ConcurrentIgnore runs the new execution regardless of running ones
+	ConcurrentIgnore ConcurrentOption = "ignore"
+	// ConcurrentPipeline runs the new execution but blocks its jobs until the running execution is far enough along,
+	// see ExecutionOptions.PipelineLevel
+	ConcurrentPipeline ConcurrentOption = "pipeline"
+	// ConcurrentSkip doesn't run the new execution if the flow is already running
+	ConcurrentSkip ConcurrentOption = "skip"
+)
+
+// ParseConcurrentOption parses a concurrent option as accepted by Azkaban.
+func ParseConcurrentOption(s string) (ConcurrentOption, error) {
+	switch o := ConcurrentOption(strings.ToLower(s)); o {
+	case ConcurrentIgnore, ConcurrentPipeline, ConcurrentSkip:
+		return o, nil
+	}
+	return "", fmt.Errorf("unknown concurrent option %q, valid are ignore, pipeline, skip", s)
+}
+
+// ExecutionOptions holds all options Azkaban accepts when executing or scheduling a flow. The zero value executes a
+// flow with the defaults configured in Azkaban.
+type ExecutionOptions struct {
+	// FlowParameters override flow and job properties for this execution
+	FlowParameters map[string]string
+	// DisabledJobs are skipped during the execution. Jobs of embedded flows are given by their nested ID, e.g.
+	// process:clean, see JobStatus.QualifiedID
+	DisabledJobs     []string
+	FailureAction    FailureAction
+	ConcurrentOption ConcurrentOption
+	// PipelineLevel is only used with ConcurrentPipeline: 1 blocks each job until the same job in the running execution
+	// finished, 2 blocks each job until the children of that job in the running execution finished.
+	PipelineLevel int
+	// SuccessEmails and FailureEmails override the notification lists configured for the flow if non-empty
+	SuccessEmails      []string
+	FailureEmails      []string
+	NotifyFailureFirst bool
+	NotifyFailureLast  bool
+}
+
+// params returns the request parameters Azkaban expects for these options.
+func (o ExecutionOptions) params() (map[string]string, error) {
+	params := make(map[string]string)
+
+	for k, v := range o.FlowParameters {
+		params[fmt.Sprintf("flowOverride[%s]", k)] = v
+	}
+
+	if len(o.DisabledJobs) > 0 {
+		disabled, err := json.Marshal(nestDisabledJobs(o.DisabledJobs))
+		if err != nil {
+			return nil, err
+		}
+		params["disabled"] = string(disabled)
+	}
+
+	if o.FailureAction != "" {
+		params["failureAction"] = string(o.FailureAction)
+	}
+
+	if o.ConcurrentOption != "" {
+		params["concurrentOption"] = string(o.ConcurrentOption)
+	}
+	if o.PipelineLevel > 0 {
+		if o.ConcurrentOption != ConcurrentPipeline {
+			return nil, fmt.Errorf("pipeline level requires concurrent option %q", ConcurrentPipeline)
+		}
+		params["pipelineLevel"] = strconv.Itoa(o.PipelineLevel)
+	}
+
+	if len(o.SuccessEmails) > 0 {
+		params["successEmails"] = strings.Join(o.SuccessEmails, ",")
+		params["successEmailsOverride"] = "true"
+	}
+	if len(o.FailureEmails) > 0 {
+		params["failureEmails"] = strings.Join(o.FailureEmails, ",")
+		params["failureEmailsOverride"] = "true"
+	}
+	if o.NotifyFailureFirst {
+		params["notifyFailureFirst"] = "true"
+	}
+	if o.NotifyFailureLast {
+		params["notifyFailureLast"] = "true"
+	}
+
+	return params, nil
+}
+
+// nestDisabledJobs converts nested job IDs to the list Azkaban expects, in which the jobs of an embedded flow are
+// disabled with an object holding the flow's ID and its disabled jobs as children.
+func nestDisabledJobs(ids []string) []interface{} {
+	disabled := []interface{}{}
+	var flows []string
+	children := make(map[string][]string)
+	for _, id := range ids {
+		i := strings.Index(id, ":")
+		if i < 0 {
+			disabled = append(disabled, id)
+			continue
+		}
+		flow := id[:i]
+		if _, ok := children[flow]; !ok {
+			flows = append(flows, flow)
+		}
+		children[flow] = append(children[flow], id[i+1:])
+	}
+	for _, flow := range flows {
+		disabled = append(disabled, map[string]interface{}{"id": flow, "children": nestDisabledJobs(children[flow])})
+	}
+	return disabled
+}
+
+// flattenDisabledJobs is the reverse of nestDisabledJobs, it returns the nested IDs of the disabled jobs prefixed with
+// prefix.
+func flattenDisabledJobs(disabled []interface{}, prefix string) []string {
+	var ids []string
+	for _, d := range disabled {
+		switch d := d.(type) {
+		case string:
+			ids = append(ids, prefix+d)
+		case map[string]interface{}:
+			id, _ := d["id"].(string)
+			children, _ := d["children"].([]interface{})
+			ids = append(ids, flattenDisabledJobs(children, prefix+id+":")...)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,79 @@
+package azkaban
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExecutionOptionsParams(t *testing.T) {
+	options := ExecutionOptions{
+		FlowParameters:   map[string]string{"date": "2019-08-01"},
+		DisabledJobs:     []string{"cleanup", "notify", "process:clean", "process:enrich:tag"},
+		FailureAction:    FinishPossible,
+		ConcurrentOption: ConcurrentPipeline,
+		PipelineLevel:    2,
+		FailureEmails:    []string{"a@example.com", "b@example.com"},
+	}
+
+	params, err := options.params()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"flowOverride[date]":    "2019-08-01",
+		"disabled":              `["cleanup","notify",{"children":["clean",{"children":["tag"],"id":"enrich"}],"id":"process"}]`,
+		"failureAction":         "finishPossible",
+		"concurrentOption":      "pipeline",
+		"pipelineLevel":         "2",
+		"failureEmails":         "a@example.com,b@example.com",
+		"failureEmailsOverride": "true",
+	}
+	if len(params) != len(expected) {
+		t.Errorf("expected %d params but got %d: %v", len(expected), len(params), params)
+	}
+	for k, v := range expected {
+		if params[k] != v {
+			t.Errorf("expected %s=%q but got %q", k, v, params[k])
+		}
+	}
+}
+
+func TestExecutionOptionsPipelineLevelRequiresPipeline(t *testing.T) {
+	_, err := ExecutionOptions{PipelineLevel: 1}.params()
+	if err == nil {
+		t.Error("expected error for pipeline level without pipeline concurrent option")
+	}
+}
+
+func TestParseFailureAction(t *testing.T) {
+	for input, expected := range map[string]FailureAction{
+		"finish-current":     FinishCurrent,
+		"finishCurrent":      FinishCurrent,
+		"cancel-immediately": CancelImmediately,
+		"finish-possible":    FinishPossible,
+		// The enum names Azkaban reports for executions
+		"FINISH_CURRENTLY_RUNNING": FinishCurrent,
+		"CANCEL_ALL":               CancelImmediately,
+		"FINISH_ALL_POSSIBLE":      FinishPossible,
+	} {
+		action, err := ParseFailureAction(input)
+		if err != nil {
+			t.Errorf("unexpected error for %q: %s", input, err)
+		}
+		if action != expected {
+			t.Errorf("expected %q for %q but got %q", expected, input, action)
+		}
+	}
+
+	if _, err := ParseFailureAction("explode"); err == nil {
+		t.Error("expected error for unknown failure action")
+	}
+}
+
+func TestFlattenDisabledJobs(t *testing.T) {
+	ids := []string{"cleanup", "process:clean", "process:enrich:tag"}
+	if flattened := flattenDisabledJobs(nestDisabledJobs(ids), ""); !reflect.DeepEqual(flattened, ids) {
+		t.Errorf("expected %v, got %v", ids, flattened)
+	}
+}
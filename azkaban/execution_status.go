@@ -0,0 +1,134 @@
+package azkaban
+
+import (
+	"sort"
+	"time"
+)
+
+// IsEmbeddedFlow returns true if the node runs an embedded flow rather than being a job itself.
+func (j JobStatus) IsEmbeddedFlow() bool {
+	return j.Type == "flow" || len(j.Nodes) > 0
+}
+
+// QualifiedID returns the nested ID of the job, or its ID if Azkaban didn't report one. Logs of jobs in embedded flows
+// are fetched by their nested ID.
+func (j JobStatus) QualifiedID() string {
+	if j.NestedID != "" {
+		return j.NestedID
+	}
+	return j.ID
+}
+
+// Duration returns how long the job ran, until now if it is still running, or 0 if it never started.
+func (j JobStatus) Duration() time.Duration {
+	if !j.started() {
+		return 0
+	}
+	return j.end().Sub(j.StartTime.Time())
+}
+
+func (j JobStatus) started() bool {
+	return j.StartTime.Time().Unix() > 0
+}
+
+// end returns when the job ended, or now if it is still running.
+func (j JobStatus) end() time.Time {
+	if j.EndTime.Time().Unix() <= 0 {
+		return time.Now()
+	}
+	return j.EndTime.Time()
+}
+
+// Walk calls f for every node of the execution depth first, embedded flows before their jobs. depth is 0 for the nodes
+// of the flow itself and one more for every embedded flow the node is part of.
+func (s FlowExecutionStatus) Walk(f func(job JobStatus, depth int)) {
+	walkNodes(s.Nodes, 0, f)
+}
+
+func walkNodes(nodes []JobStatus, depth int, f func(job JobStatus, depth int)) {
+	for _, n := range nodes {
+		f(n, depth)
+		walkNodes(n.Nodes, depth+1, f)
+	}
+}
+
+// Jobs returns all jobs of the execution including the ones of embedded flows, but not the embedded flows themselves.
+func (s FlowExecutionStatus) Jobs() []JobStatus {
+	var jobs []JobStatus
+	s.Walk(func(job JobStatus, depth int) {
+		if !job.IsEmbeddedFlow() {
+			jobs = append(jobs, job)
+		}
+	})
+	return jobs
+}
+
+// FailedJobs returns the jobs that failed. Embedded flows fail when one of their jobs does, so these are the jobs of
+// embedded flows rather than the flows themselves.
+func (s FlowExecutionStatus) FailedJobs() []JobStatus {
+	var failed []JobStatus
+	for _, j := range s.Jobs() {
+		if j.Status.IsFailure() {
+			failed = append(failed, j)
+		}
+	}
+	return failed
+}
+
+// LongestJobs returns the n jobs that ran longest, longest first.
+func (s FlowExecutionStatus) LongestJobs(n int) []JobStatus {
+	jobs := s.Jobs()
+	sort.SliceStable(jobs, func(i, j int) bool { return jobs[i].Duration() > jobs[j].Duration() })
+	if len(jobs) > n {
+		jobs = jobs[:n]
+	}
+	return jobs
+}
+
+// CriticalPath returns the chain of jobs that determined how long the execution took: the job that ended last, the job
+// it depended on that ended last, and so on, first job first. Embedded flows on the path are replaced by the critical
+// path through their jobs.
+func (s FlowExecutionStatus) CriticalPath() []JobStatus {
+	return criticalPath(s.Nodes)
+}
+
+func criticalPath(nodes []JobStatus) []JobStatus {
+	byID := make(map[string]JobStatus)
+	var last *JobStatus
+	for i, n := range nodes {
+		byID[n.ID] = n
+		if n.started() && (last == nil || n.end().After(last.end())) {
+			last = &nodes[i]
+		}
+	}
+	if last == nil {
+		return nil
+	}
+
+	var reversed []JobStatus
+	visited := make(map[string]bool)
+	for current := last; current != nil && !visited[current.ID]; {
+		visited[current.ID] = true
+		reversed = append(reversed, *current)
+
+		var next *JobStatus
+		for _, id := range current.In {
+			dep, ok := byID[id]
+			if ok && dep.started() && (next == nil || dep.end().After(next.end())) {
+				dependency := dep
+				next = &dependency
+			}
+		}
+		current = next
+	}
+
+	var path []JobStatus
+	for i := len(reversed) - 1; i >= 0; i-- {
+		if n := reversed[i]; len(n.Nodes) > 0 {
+			path = append(path, criticalPath(n.Nodes)...)
+		} else {
+			path = append(path, n)
+		}
+	}
+	return path
+}
@@ -0,0 +1,77 @@
+package azkaban
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// executionStatusJSON is a failed execution as fetchexecflow reports it. Times are seconds after the start, the
+// embedded flow process failed because its job enrich did.
+const executionStatusJSON = `{
+  "execid": 1, "flow": "daily", "status": "FAILED", "startTime": 1000, "endTime": 50000, "updateTime": 50000,
+  "nodes": [
+    {"id": "extract", "nestedId": "extract", "type": "command", "status": "SUCCEEDED", "startTime": 1000, "endTime": 10000},
+    {"id": "fetch", "nestedId": "fetch", "type": "command", "status": "SUCCEEDED", "startTime": 1000, "endTime": 4000},
+    {"id": "process", "nestedId": "process", "type": "flow", "flow": "process", "in": ["extract", "fetch"], "status": "FAILED", "startTime": 10000, "endTime": 50000,
+     "nodes": [
+       {"id": "clean", "nestedId": "process:clean", "type": "command", "status": "SUCCEEDED", "startTime": 10000, "endTime": 20000},
+       {"id": "enrich", "nestedId": "process:enrich", "type": "command", "in": ["clean"], "status": "FAILED", "startTime": 20000, "endTime": 50000},
+       {"id": "tag", "nestedId": "process:tag", "type": "command", "status": "SUCCEEDED", "startTime": 10000, "endTime": 15000}
+     ]},
+    {"id": "report", "nestedId": "report", "type": "command", "in": ["process"], "status": "CANCELLED", "startTime": -1, "endTime": -1}
+  ]
+}`
+
+func qualifiedIDs(jobs []JobStatus) []string {
+	var ids []string
+	for _, j := range jobs {
+		ids = append(ids, j.QualifiedID())
+	}
+	return ids
+}
+
+func TestFlowExecutionStatusHelpers(t *testing.T) {
+	var status FlowExecutionStatus
+	if err := json.Unmarshal([]byte(executionStatusJSON), &status); err != nil {
+		t.Fatal(err)
+	}
+
+	process := status.Nodes[2]
+	if !process.IsEmbeddedFlow() || process.EmbeddedFlowID != "process" || len(process.Nodes) != 3 || process.Duration() != 40*time.Second {
+		t.Errorf("unexpected embedded flow %v", process)
+	}
+
+	var walked []string
+	status.Walk(func(job JobStatus, depth int) {
+		if depth > 0 {
+			walked = append(walked, job.QualifiedID())
+		}
+	})
+	if expected := []string{"process:clean", "process:enrich", "process:tag"}; !reflect.DeepEqual(walked, expected) {
+		t.Errorf("expected nested jobs %v, got %v", expected, walked)
+	}
+
+	if failed := qualifiedIDs(status.FailedJobs()); !reflect.DeepEqual(failed, []string{"process:enrich"}) {
+		t.Errorf("expected the failed job of the embedded flow, got %v", failed)
+	}
+	if longest := qualifiedIDs(status.LongestJobs(2)); !reflect.DeepEqual(longest, []string{"process:enrich", "process:clean"}) {
+		t.Errorf("unexpected longest jobs %v", longest)
+	}
+	if path := qualifiedIDs(status.CriticalPath()); !reflect.DeepEqual(path, []string{"extract", "process:clean", "process:enrich"}) {
+		t.Errorf("unexpected critical path %v", path)
+	}
+
+	var update FlowExecutionUpdate
+	err := json.Unmarshal([]byte(`{"status": "RUNNING", "updateTime": 60000, "nodes": [
+		{"id": "process", "status": "RUNNING", "nodes": [{"id": "enrich", "status": "RUNNING", "startTime": 55000, "attempt": 1}]}
+	]}`), &update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	status.Apply(update)
+	if enrich := status.Nodes[2].Nodes[1]; enrich.Status != "RUNNING" || enrich.Attempt != 1 || status.Nodes[2].Status != "RUNNING" || status.Nodes[0].Status != "SUCCEEDED" {
+		t.Errorf("unexpected status after nested update %v", status)
+	}
+}
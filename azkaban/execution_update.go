@@ -0,0 +1,58 @@
+package azkaban
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// FlowExecutionUpdate returns the state of the given execution with the jobs that changed since lastUpdateTime, the
+// UpdateTime of an earlier FlowExecutionStatus or FlowExecutionUpdate, or all jobs if lastUpdateTime is unset. Watching
+// an execution this way is cheaper than fetching its whole status again.
+func (c *Client) FlowExecutionUpdate(executionID int64, lastUpdateTime AzkabanTimestamp) (FlowExecutionUpdate, error) {
+	return c.FlowExecutionUpdateContext(context.Background(), executionID, lastUpdateTime)
+}
+
+// FlowExecutionUpdateContext is like FlowExecutionUpdate but sends its requests with the given context.
+func (c *Client) FlowExecutionUpdateContext(ctx context.Context, executionID int64, lastUpdateTime AzkabanTimestamp) (FlowExecutionUpdate, error) {
+	params := make(map[string]string)
+	params["ajax"] = "fetchexecflowupdate"
+	params["execid"] = strconv.FormatInt(executionID, 10)
+	// Azkaban reports unset times as -1
+	lastUpdate := int64(-1)
+	if t := lastUpdateTime.Time(); t.Unix() > 0 {
+		lastUpdate = t.UnixNano() / int64(time.Millisecond)
+	}
+	params["lastUpdateTime"] = strconv.FormatInt(lastUpdate, 10)
+
+	update := FlowExecutionUpdate{}
+	err := c.requestAndDecode(ctx, "GET", "executor", params, &update)
+	return update, err
+}
+
+// Apply updates the status with the changes of the given update.
+func (s *FlowExecutionStatus) Apply(update FlowExecutionUpdate) {
+	s.Status = update.Status
+	s.StartTime = update.StartTime
+	s.EndTime = update.EndTime
+	s.UpdateTime = update.UpdateTime
+	applyNodes(s.Nodes, update.Nodes)
+}
+
+// applyNodes updates the jobs with the changed ones, and the jobs of embedded flows with their changed jobs.
+func applyNodes(nodes []JobStatus, changes []JobStatus) {
+	for _, changed := range changes {
+		for i := range nodes {
+			node := &nodes[i]
+			if node.ID != changed.ID {
+				continue
+			}
+			node.Status = changed.Status
+			node.StartTime = changed.StartTime
+			node.EndTime = changed.EndTime
+			node.UpdateTime = changed.UpdateTime
+			node.Attempt = changed.Attempt
+			applyNodes(node.Nodes, changed.Nodes)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package azkaban
+
+// FlowGraph is the dependency graph of a flow's jobs, with Next and Prev of every job linked.
+type FlowGraph struct {
+	FlowID string
+	// Jobs are the flow's jobs ordered so every job comes after the jobs it depends on, otherwise in the order Azkaban
+	// reported them
+	Jobs []*FlowJob
+	byID map[string]*FlowJob
+}
+
+// Graph builds the dependency graph of the flow's jobs. Dependencies on jobs that aren't part of the flow are ignored.
+func (l FlowJobList) Graph() *FlowGraph {
+	g := &FlowGraph{FlowID: l.FlowID, byID: make(map[string]*FlowJob)}
+	var jobs []*FlowJob
+	for _, n := range l.Nodes {
+		job := n
+		job.Next, job.Prev = nil, nil
+		jobs = append(jobs, &job)
+		g.byID[job.ID] = &job
+	}
+	for _, job := range jobs {
+		for _, id := range job.In {
+			if dep, ok := g.byID[id]; ok {
+				job.Prev = append(job.Prev, dep)
+				dep.Next = append(dep.Next, job)
+			}
+		}
+	}
+
+	// Kahn's algorithm, picking ready jobs in the reported order so the result is stable
+	remaining := make(map[*FlowJob]int)
+	for _, job := range jobs {
+		remaining[job] = len(job.Prev)
+	}
+	added := make(map[*FlowJob]bool)
+	for len(g.Jobs) < len(jobs) {
+		progress := false
+		for _, job := range jobs {
+			if added[job] || remaining[job] > 0 {
+				continue
+			}
+			added[job] = true
+			progress = true
+			g.Jobs = append(g.Jobs, job)
+			for _, next := range job.Next {
+				remaining[next]--
+			}
+		}
+		if !progress {
+			// Only cycles are left, which Azkaban doesn't allow anyway
+			for _, job := range jobs {
+				if !added[job] {
+					added[job] = true
+					g.Jobs = append(g.Jobs, job)
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+// Job returns the job with the given ID, or nil.
+func (g *FlowGraph) Job(id string) *FlowJob {
+	return g.byID[id]
+}
+
+// Roots returns the jobs that don't depend on other jobs.
+func (g *FlowGraph) Roots() []*FlowJob {
+	var roots []*FlowJob
+	for _, job := range g.Jobs {
+		if len(job.Prev) == 0 {
+			roots = append(roots, job)
+		}
+	}
+	return roots
+}
+
+// Leaves returns the jobs no other jobs depend on, the flow is done when they are.
+func (g *FlowGraph) Leaves() []*FlowJob {
+	var leaves []*FlowJob
+	for _, job := range g.Jobs {
+		if len(job.Next) == 0 {
+			leaves = append(leaves, job)
+		}
+	}
+	return leaves
+}
+
+// Levels returns how deep each job is in the graph: 0 for jobs without dependencies, otherwise one more than the
+// deepest job it depends on.
+func (g *FlowGraph) Levels() map[string]int {
+	levels := make(map[string]int)
+	for _, job := range g.Jobs {
+		level := 0
+		for _, dep := range job.Prev {
+			if l, ok := levels[dep.ID]; ok && l+1 > level {
+				level = l + 1
+			}
+		}
+		levels[job.ID] = level
+	}
+	return levels
+}
@@ -0,0 +1,50 @@
+package azkaban
+
+import (
+	"reflect"
+	"testing"
+)
+
+func jobIDs(jobs []*FlowJob) []string {
+	var ids []string
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	return ids
+}
+
+func TestFlowJobListGraph(t *testing.T) {
+	list := FlowJobList{FlowID: "daily", Nodes: []FlowJob{
+		{ID: "load", In: []string{"transform", "fetch"}},
+		{ID: "transform", In: []string{"extract", "gone"}},
+		{ID: "extract"},
+		{ID: "fetch"},
+	}}
+
+	g := list.Graph()
+	if ids := jobIDs(g.Jobs); !reflect.DeepEqual(ids, []string{"extract", "fetch", "transform", "load"}) {
+		t.Errorf("expected jobs after their dependencies, got %v", ids)
+	}
+	if ids := jobIDs(g.Roots()); !reflect.DeepEqual(ids, []string{"extract", "fetch"}) {
+		t.Errorf("unexpected roots %v", ids)
+	}
+	if ids := jobIDs(g.Leaves()); !reflect.DeepEqual(ids, []string{"load"}) {
+		t.Errorf("unexpected leaves %v", ids)
+	}
+
+	transform := g.Job("transform")
+	if prev := jobIDs(transform.Prev); !reflect.DeepEqual(prev, []string{"extract"}) {
+		t.Errorf("expected transform to depend on extract only, got %v", prev)
+	}
+	if next := jobIDs(transform.Next); !reflect.DeepEqual(next, []string{"load"}) || transform.Next[0] != g.Job("load") {
+		t.Errorf("expected transform to link to load, got %v", next)
+	}
+	if list.Nodes[1].Next != nil {
+		t.Errorf("expected the job list to be left alone")
+	}
+
+	expected := map[string]int{"extract": 0, "fetch": 0, "transform": 1, "load": 2}
+	if levels := g.Levels(); !reflect.DeepEqual(levels, expected) {
+		t.Errorf("expected levels %v, got %v", expected, levels)
+	}
+}
@@ -2,6 +2,7 @@ package azkaban
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/ilikeorangutans/harbormaster/format"
 	"strconv"
@@ -30,6 +31,7 @@ func (t AzkabanTimestamp) Time() time.Time {
 }
 
 type LoginResponse struct {
+	AzkabanResponse
 	Status    string `json:"status"`
 	SessionID string `json:"session.id"`
 }
@@ -196,6 +198,19 @@ func (e Execution) Duration() time.Duration {
 	return endTime.Sub(e.StartTime.Time())
 }
 
+type ExecuteFlowResponse struct {
+	AzkabanResponse
+	Project     string `json:"project"`
+	Flow        string `json:"flow"`
+	ExecutionID int64  `json:"execid"`
+	Message     string `json:"message"`
+}
+
+type RunningExecutionsResponse struct {
+	AzkabanResponse
+	ExecutionIDs []int64 `json:"execIds"`
+}
+
 type FlowJobList struct {
 	AzkabanResponse
 	Nodes     []FlowJob `json:"nodes"`
@@ -207,17 +222,30 @@ type FlowJob struct {
 	ID   string   `json:"id"`
 	Type string   `json:"type"`
 	In   []string `json:"in"`
-	Next *FlowJob
-	Prev *FlowJob
+	// Next are the jobs depending on this one and Prev the ones it depends on, linked by FlowJobList.Graph
+	Next []*FlowJob `json:"-"`
+	Prev []*FlowJob `json:"-"`
+}
+
+// JobInfo are the properties of a deployed job. GeneralParams are the properties from the uploaded project,
+// OverrideParams the ones changed in Azkaban's web UI since.
+type JobInfo struct {
+	AzkabanResponse
+	JobName        string            `json:"jobName"`
+	JobType        string            `json:"jobType"`
+	GeneralParams  map[string]string `json:"generalParams"`
+	OverrideParams map[string]string `json:"overrideParams"`
 }
 
 type FlowJobLog struct {
+	AzkabanResponse
 	Data   string `json:"data"`
 	Length int64  `json:"length"`
 	Offset int64  `json:"offset"`
 }
 
 type ScheduleResponse struct {
+	AzkabanResponse
 	Schedule *FlowSchedule `json:"schedule"`
 }
 
@@ -226,25 +254,173 @@ func (s ScheduleResponse) Empty() bool {
 }
 
 type FlowSchedule struct {
-	ID           string            `json:"scheduleId"`
-	NextExecTime AzkabanStringTime `json:"nextExecTime"`
-	Period       string            `json:"period"` // TODO make this a time.Duration
+	ID             string            `json:"scheduleId"`
+	SubmitUser     string            `json:"submitUser"`
+	NextExecTime   AzkabanStringTime `json:"nextExecTime"`
+	Period         SchedulePeriod    `json:"period"`
+	CronExpression CronExpression    `json:"cronExpression"`
 }
 
 func (f FlowSchedule) IsScheduled() bool {
 	return f.NextExecTime.Time().Unix() > 0
 }
 
+// Schedule returns the cron expression of cron based schedules, and a periodic schedule starting at the next execution
+// otherwise. Returns nil if the flow is not scheduled.
+func (f FlowSchedule) Schedule() Schedule {
+	if !f.IsScheduled() {
+		return nil
+	}
+	if !f.CronExpression.IsZero() {
+		return f.CronExpression
+	}
+	return PeriodicSchedule{Start: f.NextExecTime.Time(), Period: f.Period}
+}
+
+// NextExecutions computes the next n execution times locally. Cron expressions are evaluated in ServerLocation. Returns
+// nothing for cron expressions that couldn't be parsed, see CronExpression.Err.
+func (f FlowSchedule) NextExecutions(n int) []time.Time {
+	schedule := f.Schedule()
+	if schedule == nil {
+		return nil
+	}
+	return NextN(schedule, time.Now().In(ServerLocation), n)
+}
+
+// Description returns a human readable description of the schedule, either the cron expression or the period.
+func (f FlowSchedule) Description() string {
+	if !f.CronExpression.IsZero() {
+		return f.CronExpression.String()
+	}
+	return f.Period.String()
+}
+
+type ScheduleFlowResponse struct {
+	AzkabanResponse
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	ScheduleID int64  `json:"scheduleId"`
+}
+
+// AzkabanError returns the error of a schedule response; the schedule endpoints report some errors in message.
+func (r ScheduleFlowResponse) AzkabanError() string {
+	if r.Error == "" && r.Status == "error" {
+		return r.Message
+	}
+	return r.Error
+}
+
+type CreateProjectResponse struct {
+	AzkabanResponse
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Path    string `json:"path"`
+}
+
+// AzkabanError returns the error of a create response; Azkaban reports it in message.
+func (r CreateProjectResponse) AzkabanError() string {
+	if r.Error == "" && r.Status == "error" {
+		return r.Message
+	}
+	return r.Error
+}
+
+// UploadProjectResponse is Azkaban's answer to a project upload. Azkaban reports IDs and versions as strings here.
+type UploadProjectResponse struct {
+	AzkabanResponse
+	ProjectID string `json:"projectId"`
+	Version   string `json:"version"`
+	// Warnings are Azkaban's validation warnings about the uploaded files, if any
+	Warnings string `json:"warn"`
+}
+
+// ProjectLogsResponse is the event log of a project, most recent first. Every row of LogData has a value for each of
+// the Columns.
+type ProjectLogsResponse struct {
+	AzkabanResponse
+	Project   string              `json:"project"`
+	ProjectID int64               `json:"projectId"`
+	Columns   []string            `json:"columns"`
+	LogData   [][]json.RawMessage `json:"logData"`
+}
+
+// Events returns the rows of the log.
+func (r ProjectLogsResponse) Events() ([]ProjectEvent, error) {
+	events := []ProjectEvent{}
+	for _, row := range r.LogData {
+		event := ProjectEvent{}
+		for i, column := range r.Columns {
+			if i >= len(row) {
+				break
+			}
+			var err error
+			switch column {
+			case "user":
+				err = json.Unmarshal(row[i], &event.User)
+			case "time":
+				var t AzkabanTimestamp
+				err = json.Unmarshal(row[i], &t)
+				event.Time = t.Time()
+			case "type":
+				err = json.Unmarshal(row[i], &event.Type)
+			case "message":
+				err = json.Unmarshal(row[i], &event.Message)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("project log column %s: %s", column, err)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// ProjectEvent is an entry of a project's log, like an upload or a change of permissions.
+type ProjectEvent struct {
+	User string
+	Time time.Time
+	// Type is e.g. CREATED, UPLOADED, SCHEDULE, or PROPERTY_OVERRIDE
+	Type    string
+	Message string
+}
+
+type ListSchedulesResponse struct {
+	AzkabanResponse
+	Schedules []ScheduledFlow `json:"items"`
+}
+
+// ScheduledFlow is a schedule as listed by Azkaban's schedule overview
+type ScheduledFlow struct {
+	ID             int64            `json:"scheduleid"`
+	Project        string           `json:"projectname"`
+	FlowID         string           `json:"flowname"`
+	FirstSchedTime AzkabanTimestamp `json:"time"`
+	CronExpression string           `json:"cron"`
+	// Period is the period of non-cron schedules in milliseconds
+	Period int64 `json:"period"`
+}
+
+// ServerLocation is the timezone the Azkaban server runs in. Azkaban formats some times without timezone and evaluates
+// cron expressions in this timezone.
+var ServerLocation = serverLocation()
+
+func serverLocation() *time.Location {
+	// Because azkaban for some reason runs in EST
+	loc, err := time.LoadLocation("EST")
+	if err != nil {
+		return time.FixedZone("EST", -5*60*60)
+	}
+	return loc
+}
+
 type AzkabanStringTime time.Time
 
 func (t *AzkabanStringTime) UnmarshalJSON(b []byte) error {
-	// Because azkaban for some reason runs in EST
-	loc, _ := time.LoadLocation("EST")
 	unquoted, err := strconv.Unquote(string(b))
 	if err != nil {
 		return err
 	}
-	x, err := time.ParseInLocation("2006-01-02 15:04:05", unquoted, loc)
+	x, err := time.ParseInLocation("2006-01-02 15:04:05", unquoted, ServerLocation)
 	if err != nil {
 		return err
 	}
@@ -259,23 +435,107 @@ func (t AzkabanStringTime) Time() time.Time {
 }
 
 type FlowExecutionStatus struct {
-	Attempt   int         `json:"attempt"`
-	Status    Status      `json:"status"`
-	ProjectID int64       `json:"projectId"`
-	FlowID    string      `json:"flow"`
-	Nodes     []JobStatus `json:"nodes"`
+	AzkabanResponse
+	ExecutionID int64            `json:"execid"`
+	Attempt     int              `json:"attempt"`
+	Status      Status           `json:"status"`
+	Project     string           `json:"project"`
+	ProjectID   int64            `json:"projectId"`
+	FlowID      string           `json:"flow"`
+	SubmitTime  AzkabanTimestamp `json:"submitTime"`
+	StartTime   AzkabanTimestamp `json:"startTime"`
+	EndTime     AzkabanTimestamp `json:"endTime"`
+	// UpdateTime is when Azkaban last changed the execution, pass it to FlowExecutionUpdate to get later changes
+	UpdateTime AzkabanTimestamp `json:"updateTime"`
+	Nodes      []JobStatus      `json:"nodes"`
+}
+
+// Execution returns the summary of this execution as listed in a flow's executions
+func (s FlowExecutionStatus) Execution() Execution {
+	return Execution{
+		SubmitTime: s.SubmitTime,
+		StartTime:  s.StartTime,
+		Status:     s.Status,
+		ID:         s.ExecutionID,
+		EndTime:    s.EndTime,
+		ProjectID:  s.ProjectID,
+	}
 }
 
+// JobStatus is the status of a job of an execution, or of an embedded flow with the statuses of its jobs.
 type JobStatus struct {
-	ID     string `json:"id"`
-	Status Status `json:"status"`
+	ID string `json:"id"`
+	// NestedID is the ID within the execution, e.g. embedded:job for jobs of embedded flows
+	NestedID   string           `json:"nestedId"`
+	Type       string           `json:"type"`
+	In         []string         `json:"in"`
+	Status     Status           `json:"status"`
+	StartTime  AzkabanTimestamp `json:"startTime"`
+	EndTime    AzkabanTimestamp `json:"endTime"`
+	UpdateTime AzkabanTimestamp `json:"updateTime"`
+	// Attempt counts the retries of the job, starting at 0
+	Attempt int `json:"attempt"`
+	// EmbeddedFlowID is the flow an embedded flow runs, empty for jobs
+	EmbeddedFlowID string `json:"flow"`
+	// Nodes are the jobs of an embedded flow
+	Nodes []JobStatus `json:"nodes"`
 }
 
-type ListAllProjectsResponse struct {
-	Projects []Project `json:"projects"`
-	Error    string    `json:"error"`
+// FlowExecutionUpdate is the state of an execution with only the jobs that changed since an earlier update.
+type FlowExecutionUpdate struct {
+	AzkabanResponse
+	FlowID     string           `json:"id"`
+	Status     Status           `json:"status"`
+	StartTime  AzkabanTimestamp `json:"startTime"`
+	EndTime    AzkabanTimestamp `json:"endTime"`
+	UpdateTime AzkabanTimestamp `json:"updateTime"`
+	Nodes      []JobStatus      `json:"nodes"`
+}
+
+// ExecutionInfo holds the options an execution was submitted with
+type ExecutionInfo struct {
+	AzkabanResponse
+	FlowParameters        map[string]string `json:"flowParam"`
+	SuccessEmails         []string          `json:"successEmails"`
+	FailureEmails         []string          `json:"failureEmails"`
+	SuccessEmailsOverride bool              `json:"successEmailsOverride"`
+	FailureEmailsOverride bool              `json:"failureEmailsOverride"`
+	NotifyFailureFirst    bool              `json:"notifyFailureFirst"`
+	NotifyFailureLast     bool              `json:"notifyFailureLast"`
+	// FailureAction is the name of Azkaban's enum, e.g. FINISH_CURRENTLY_RUNNING, not what executeFlow accepts
+	FailureAction    FailureAction    `json:"failureAction"`
+	ConcurrentOption ConcurrentOption `json:"concurrentOptions"`
+	PipelineLevel    int              `json:"pipelineLevel"`
+	// Disabled contains job names for disabled jobs and objects for jobs disabled in embedded flows
+	Disabled   []interface{}     `json:"disabled"`
+	NodeStatus map[string]Status `json:"nodeStatus"`
+}
+
+// ExecutionOptions returns options that submit a new execution the same way as the one this info was fetched for.
+func (i ExecutionInfo) ExecutionOptions() ExecutionOptions {
+	options := ExecutionOptions{
+		FlowParameters:     i.FlowParameters,
+		ConcurrentOption:   i.ConcurrentOption,
+		NotifyFailureFirst: i.NotifyFailureFirst,
+		NotifyFailureLast:  i.NotifyFailureLast,
+	}
+	if i.ConcurrentOption == ConcurrentPipeline {
+		options.PipelineLevel = i.PipelineLevel
+	}
+	if i.SuccessEmailsOverride {
+		options.SuccessEmails = i.SuccessEmails
+	}
+	if i.FailureEmailsOverride {
+		options.FailureEmails = i.FailureEmails
+	}
+	// Unknown failure actions are left to Azkaban's default
+	options.FailureAction, _ = ParseFailureAction(string(i.FailureAction))
+	options.DisabledJobs = flattenDisabledJobs(i.Disabled, "")
+
+	return options
 }
 
-func (l *ListAllProjectsResponse) AzkabanError() string {
-	return ""
+type ListAllProjectsResponse struct {
+	AzkabanResponse
+	Projects []Project `json:"projects"`
 }
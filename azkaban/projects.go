@@ -1,7 +1,6 @@
 package azkaban
 
 import (
-	"errors"
 	"fmt"
 )
 
@@ -31,7 +30,12 @@ func (r *projectRepoImpl) ByName(name string) (Project, error) {
 			return p, nil
 		}
 	}
-	return Project{}, errors.New(fmt.Sprintf("no project with name %s", name))
+	return Project{}, &APIError{
+		Endpoint: "index?ajax=fetchallprojects",
+		Method:   "GET",
+		Message:  fmt.Sprintf("no project with name %s", name),
+		Err:      ErrNotFound,
+	}
 }
 
 func (r *projectRepoImpl) ListProjects() ([]Project, error) {
@@ -0,0 +1,51 @@
+package azkaban
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter spaces requests evenly, it doesn't allow bursts.
+type rateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// reserve returns how long the caller has to wait before sending its request.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	return wait
+}
+
+// wait blocks until the caller may send its request or the context is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	timer := time.NewTimer(l.reserve())
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetRateLimit limits the requests this client sends to Azkaban to the given number per second, shared by all
+// goroutines using the client. Zero or less removes the limit.
+func (c *Client) SetRateLimit(requestsPerSecond float64) {
+	if requestsPerSecond <= 0 {
+		c.rateLimiter = nil
+		return
+	}
+	c.rateLimiter = &rateLimiter{interval: time.Duration(float64(time.Second) / requestsPerSecond)}
+}
@@ -0,0 +1,18 @@
+package azkaban
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRateLimiterSpacesRequests(t *testing.T) {
+	l := &rateLimiter{interval: time.Second}
+
+	for i := 0; i < 3; i++ {
+		wait := l.reserve()
+		expected := time.Duration(i) * time.Second
+		if wait > expected || wait < expected-100*time.Millisecond {
+			t.Errorf("request %d: expected to wait about %s, got %s", i, expected, wait)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package azkaban
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy decides how often and how far apart the client retries requests that failed with a connection error or
+// a HTTP 5xx status, e.g. while Azkaban's web server restarts. Only requests that don't change anything in Azkaban are
+// retried, so executing, cancelling, pausing, or resuming flows is attempted once.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts including the first one, 1 or less disables retries
+	MaxAttempts int
+	// InitialBackoff is the wait before the first retry, it doubles with every further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries
+	MaxBackoff time.Duration
+	// Jitter is the fraction by which waits are randomized, e.g. 0.2 waits between 80% and 120% of the backoff
+	Jitter float64
+}
+
+// DefaultRetryPolicy is the retry policy of new clients, it gives up after about 4 seconds.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+	Jitter:         0.2,
+}
+
+// NoRetries is a retry policy that attempts every request once.
+var NoRetries = RetryPolicy{MaxAttempts: 1}
+
+// backoff returns how long to wait before the given retry, starting at 1.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	backoff := p.InitialBackoff
+	for i := 1; i < retry; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			backoff = p.MaxBackoff
+			break
+		}
+	}
+
+	return time.Duration(float64(backoff) * (1 + p.Jitter*(2*rand.Float64()-1)))
+}
+
+// nonIdempotentActions are ajax actions that change Azkaban even though they are sent as GET requests
+var nonIdempotentActions = map[string]bool{
+	"executeFlow": true,
+	"cancelFlow":  true,
+	"pauseFlow":   true,
+	"resumeFlow":  true,
+}
+
+// isIdempotent returns true for requests that can be repeated safely. Deleting a project is a GET request without an
+// ajax action.
+func isIdempotent(method string, params map[string]string) bool {
+	return method == "GET" && !nonIdempotentActions[params["ajax"]] && params["delete"] != "true"
+}
+
+// retryCause returns why the given attempt should be retried, or nil if it succeeded or failed for good.
+func retryCause(ctx context.Context, method string, path string, params map[string]string, resp *http.Response, err error) error {
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	if resp.StatusCode >= 500 {
+		return newAPIError(method, path, params, resp.StatusCode, "")
+	}
+	return nil
+}
+
+// withRetries sends the request with do and retries it according to the client's retry policy.
+func (c *Client) withRetries(ctx context.Context, method string, path string, params map[string]string, do func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := do()
+		if attempt >= c.RetryPolicy.MaxAttempts || !isIdempotent(method, params) {
+			return resp, err
+		}
+		cause := retryCause(ctx, method, path, params, resp, err)
+		if cause == nil {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		wait := c.RetryPolicy.backoff(attempt)
+		if c.OnRetry != nil {
+			c.OnRetry(attempt, wait, cause)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package azkaban
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RetryPlan describes a new execution that reruns only the jobs of a previous execution that did not succeed.
+type RetryPlan struct {
+	// ExecutionID is the execution being retried
+	ExecutionID int64
+	Project     string
+	Flow        string
+	// Options are the options of the retried execution with all succeeded jobs disabled
+	Options ExecutionOptions
+}
+
+// PlanRetry builds a RetryPlan for the given finished execution. The new execution uses the same flow parameters and
+// options as the given one, with every job that already succeeded disabled, including the jobs of embedded flows.
+func (c *Client) PlanRetry(executionID int64) (RetryPlan, error) {
+	return c.PlanRetryContext(context.Background(), executionID)
+}
+
+// PlanRetryContext is like PlanRetry but sends its requests with the given context.
+func (c *Client) PlanRetryContext(ctx context.Context, executionID int64) (RetryPlan, error) {
+	plan := RetryPlan{ExecutionID: executionID}
+
+	status, err := c.FlowExecutionStatusContext(ctx, executionID)
+	if err != nil {
+		return plan, err
+	}
+	switch status.Status {
+	case "SUCCEEDED":
+		return plan, fmt.Errorf("execution %d succeeded, nothing to retry", executionID)
+	case "RUNNING", "PREPARING", "PAUSED":
+		return plan, fmt.Errorf("execution %d is still %s", executionID, status.Status)
+	}
+	plan.Project = status.Project
+	plan.Flow = status.FlowID
+
+	info, err := c.ExecutionInfoContext(ctx, executionID)
+	if err != nil {
+		return plan, err
+	}
+	plan.Options = info.ExecutionOptions()
+
+	// Only disable jobs that are still part of the flow, it might have been redeployed since
+	jobs, err := c.FlowJobListContext(ctx, status.Project, status.FlowID)
+	if err != nil {
+		return plan, err
+	}
+	exists := make(map[string]bool)
+	for _, job := range jobs.Nodes {
+		exists[job.ID] = true
+	}
+
+	disabled := make(map[string]bool)
+	for _, job := range plan.Options.DisabledJobs {
+		disabled[job] = true
+	}
+	// Embedded flows that succeeded are disabled as a whole, otherwise their jobs that succeeded are. covered are the
+	// embedded flows and jobs disabled along with a flow containing them, or no longer part of the flow.
+	covered := make(map[string]bool)
+	status.Walk(func(job JobStatus, depth int) {
+		id := job.QualifiedID()
+		if depth == 0 && !exists[job.ID] {
+			covered[id] = true
+			return
+		}
+		if depth > 0 {
+			parent := id[:strings.LastIndex(id, ":")]
+			if disabled[parent] || covered[parent] {
+				covered[id] = true
+				return
+			}
+		}
+		if job.Status.IsSuccess() {
+			disabled[id] = true
+		}
+	})
+
+	plan.Options.DisabledJobs = nil
+	for job := range disabled {
+		plan.Options.DisabledJobs = append(plan.Options.DisabledJobs, job)
+	}
+	sort.Strings(plan.Options.DisabledJobs)
+
+	return plan, nil
+}
+
+// RetryFailedJobs submits a new execution that reruns only the jobs of the given execution that did not succeed.
+func (c *Client) RetryFailedJobs(executionID int64) (ExecuteFlowResponse, error) {
+	return c.RetryFailedJobsContext(context.Background(), executionID)
+}
+
+// RetryFailedJobsContext is like RetryFailedJobs but sends its requests with the given context.
+func (c *Client) RetryFailedJobsContext(ctx context.Context, executionID int64) (ExecuteFlowResponse, error) {
+	plan, err := c.PlanRetryContext(ctx, executionID)
+	if err != nil {
+		return ExecuteFlowResponse{}, err
+	}
+
+	return c.ExecuteFlowContext(ctx, plan.Project, plan.Flow, plan.Options)
+}
@@ -0,0 +1,38 @@
+package azkaban
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, e := range expected {
+		if backoff := p.backoff(i + 1); backoff != e {
+			t.Errorf("retry %d: expected %s, got %s", i+1, e, backoff)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if backoff := p.backoff(1); backoff < 500*time.Millisecond || backoff > 1500*time.Millisecond {
+			t.Fatalf("expected backoff within jitter, got %s", backoff)
+		}
+	}
+}
+
+func TestIsIdempotent(t *testing.T) {
+	if !isIdempotent("GET", map[string]string{"ajax": "fetchexecflow"}) {
+		t.Error("expected fetchexecflow to be idempotent")
+	}
+	if isIdempotent("GET", map[string]string{"ajax": "executeFlow"}) {
+		t.Error("expected executeFlow not to be idempotent")
+	}
+	if isIdempotent("GET", map[string]string{"project": "example", "delete": "true"}) {
+		t.Error("expected project deletes not to be idempotent")
+	}
+	if isIdempotent("POST", map[string]string{"ajax": "scheduleCronFlow"}) {
+		t.Error("expected POST not to be idempotent")
+	}
+}
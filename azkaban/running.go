@@ -0,0 +1,317 @@
+package azkaban
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	htmlx "golang.org/x/net/html"
+)
+
+type FlowExecution struct {
+	FlowID  string
+	Project string
+	Execution
+}
+
+// Running returns the currently running executions of all projects the user may read. Azkaban has no server-wide
+// listing, so this takes one request for the projects, one per project for its flows, one per flow for its running
+// executions, and one per running execution for its details, sent a few at a time in parallel. On large servers that's
+// a lot of requests, use RunningInProject where possible.
+// If the JSON API fails, Running falls back to scraping the executor page of the web ui.
+func (c *Client) Running() ([]FlowExecution, error) {
+	return c.RunningContext(context.Background())
+}
+
+// RunningContext is like Running but sends its requests with the given context.
+func (c *Client) RunningContext(ctx context.Context) ([]FlowExecution, error) {
+	executions, err := c.runningFromAPI(ctx, "")
+	// Only fall back if the API failed, not if Azkaban rejected the request or it was cancelled
+	if err == nil || err == ErrSessionExpired || IsNotFound(err) || IsPermissionDenied(err) || ctx.Err() != nil {
+		return executions, err
+	}
+
+	log.Printf("could not fetch running executions, falling back to executor page: %s", err)
+	return c.runningFromExecutorPage(ctx, "")
+}
+
+// RunningInProject returns the currently running executions of the given project. This takes one request for the
+// project's flows, one per flow, and one per running execution, see Running.
+func (c *Client) RunningInProject(project string) ([]FlowExecution, error) {
+	return c.RunningInProjectContext(context.Background(), project)
+}
+
+// RunningInProjectContext is like RunningInProject but sends its requests with the given context.
+func (c *Client) RunningInProjectContext(ctx context.Context, project string) ([]FlowExecution, error) {
+	executions, err := c.runningFromAPI(ctx, project)
+	// Only fall back if the API failed, not if Azkaban rejected the request or it was cancelled
+	if err == nil || err == ErrSessionExpired || IsNotFound(err) || IsPermissionDenied(err) || ctx.Err() != nil {
+		return executions, err
+	}
+
+	log.Printf("could not fetch running executions, falling back to executor page: %s", err)
+	return c.runningFromExecutorPage(ctx, project)
+}
+
+// runningConcurrency is how many requests Running sends in parallel
+const runningConcurrency = 4
+
+// runningFromAPI collects running executions through getRunning and fetchexecflow, for all projects if project is
+// empty. Projects the user may not read are skipped then.
+func (c *Client) runningFromAPI(ctx context.Context, project string) ([]FlowExecution, error) {
+	projects := []string{project}
+	if project == "" {
+		all, err := c.ListProjectsContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		projects = nil
+		for _, p := range all {
+			projects = append(projects, p.Name)
+		}
+	}
+
+	flowsByProject := make([][]Flow, len(projects))
+	err := ForEachConcurrently(len(projects), runningConcurrency, func(i int) error {
+		flows, err := c.ListFlowsContext(ctx, projects[i])
+		if project == "" && IsPermissionDenied(err) {
+			log.Printf("skipping project %s: %s", projects[i], err)
+			return nil
+		}
+		flowsByProject[i] = flows
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var flows []FlowExecution
+	for i, p := range projects {
+		for _, f := range flowsByProject[i] {
+			flows = append(flows, FlowExecution{FlowID: f.FlowID, Project: p})
+		}
+	}
+	idsByFlow := make([][]int64, len(flows))
+	err = ForEachConcurrently(len(flows), runningConcurrency, func(i int) error {
+		ids, err := c.RunningExecutionsContext(ctx, flows[i].Project, flows[i].FlowID)
+		idsByFlow[i] = ids
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	executions := []FlowExecution{}
+	for i, f := range flows {
+		for _, id := range idsByFlow[i] {
+			f.ID = id
+			executions = append(executions, f)
+		}
+	}
+	err = ForEachConcurrently(len(executions), runningConcurrency, func(i int) error {
+		status, err := c.FlowExecutionStatusContext(ctx, executions[i].ID)
+		executions[i].Execution = status.Execution()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return executions, nil
+}
+
+// runningFromExecutorPage scrapes the running executions table of the executor page, optionally only keeping
+// executions of the given project.
+func (c *Client) runningFromExecutorPage(ctx context.Context, project string) ([]FlowExecution, error) {
+	var executions []FlowExecution
+	err := c.withSession(ctx, func() error {
+		resp, err := c.request(ctx, "GET", "executor", nil)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != 200 {
+			return newAPIError("GET", "executor", nil, resp.StatusCode, "")
+		}
+
+		doc, err := htmlx.Parse(resp.Body)
+		if err != nil {
+			return err
+		}
+
+		executions, err = parseExecutorPage(doc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The project ID is not part of the page, so look it up
+	projects, err := c.ListProjectsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	projectIDs := make(map[string]int64)
+	for _, p := range projects {
+		projectIDs[p.Name] = p.ID
+	}
+
+	var result []FlowExecution
+	for _, e := range executions {
+		if project != "" && e.Project != project {
+			continue
+		}
+		e.ProjectID = projectIDs[e.Project]
+		result = append(result, e)
+	}
+
+	return result, nil
+}
+
+// parseExecutorPage extracts running executions from the executor page.
+func parseExecutorPage(doc *htmlx.Node) ([]FlowExecution, error) {
+	// Azkaban serves the login page simply with a HTTP 200 so the only way to check if we're looking at the login page
+	// is by looking for the login element.
+	if findElementWithID(doc, "username") != nil && findElementWithID(doc, "password") != nil {
+		return nil, ErrSessionExpired
+	}
+
+	table := findElementWithID(doc, "executingJobs")
+	if table == nil {
+		return nil, errors.New("could not find running executions on executor page")
+	}
+
+	return findExecutions(table)
+}
+
+// findExecutions reads executions from the rows of the given table. Rather than relying on column positions it
+// identifies cells by their links: the execution link has an execid parameter, the flow link a flow parameter, and
+// the project link only a project parameter. The start time is the first cell containing a timestamp.
+func findExecutions(table *htmlx.Node) ([]FlowExecution, error) {
+	executions := []FlowExecution{}
+	for _, tbody := range findElementsOfType(table, "tbody") {
+		for _, row := range findElementsOfType(tbody, "tr") {
+			execution, ok, err := parseExecutionRow(row)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				executions = append(executions, execution)
+			}
+		}
+	}
+
+	return executions, nil
+}
+
+func parseExecutionRow(row *htmlx.Node) (FlowExecution, bool, error) {
+	execution := FlowExecution{
+		Execution: Execution{
+			Status: "RUNNING",
+		},
+	}
+
+	foundID := false
+	for _, a := range findElementsOfType(row, "a") {
+		href, err := url.Parse(attribute(a, "href"))
+		if err != nil {
+			continue
+		}
+		query := href.Query()
+		switch {
+		case query.Get("execid") != "":
+			id, err := strconv.ParseInt(query.Get("execid"), 10, 64)
+			if err != nil {
+				return execution, false, fmt.Errorf("invalid execution id in %q: %s", href, err)
+			}
+			execution.ID = id
+			foundID = true
+		case query.Get("flow") != "":
+			execution.FlowID = query.Get("flow")
+		case query.Get("project") != "":
+			execution.Project = query.Get("project")
+		}
+	}
+	// Rows without execution link are placeholders like "No running flows"
+	if !foundID {
+		return execution, false, nil
+	}
+
+	for _, cell := range findElementsOfType(row, "td") {
+		startTime, err := time.ParseInLocation("2006-01-02 15:04:05", strings.TrimSpace(textContent(cell)), ServerLocation)
+		if err == nil {
+			execution.StartTime = AzkabanTimestamp(startTime)
+			break
+		}
+	}
+
+	for _, n := range findElementsOfType(row, "div") {
+		if strings.Contains(attribute(n, "class"), "status") {
+			if status := strings.TrimSpace(textContent(n)); status != "" {
+				execution.Status = Status(status)
+			}
+		}
+	}
+
+	return execution, true, nil
+}
+
+func findElementsOfType(n *htmlx.Node, t string) []*htmlx.Node {
+	var result []*htmlx.Node
+	if n.Type == htmlx.ElementNode && n.Data == t {
+		result = append(result, n)
+	}
+
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		result = append(result, findElementsOfType(c, t)...)
+	}
+
+	return result
+}
+
+func findElementWithID(n *htmlx.Node, id string) *htmlx.Node {
+	if hasAttribute(n, "id", id) {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if result := findElementWithID(c, id); result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+func hasAttribute(n *htmlx.Node, name string, value string) bool {
+	for _, a := range n.Attr {
+		if a.Key == name && a.Val == value {
+			return true
+		}
+	}
+	return false
+}
+
+func attribute(n *htmlx.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func textContent(n *htmlx.Node) string {
+	if n.Type == htmlx.TextNode {
+		return n.Data
+	}
+
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
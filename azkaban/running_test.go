@@ -0,0 +1,64 @@
+package azkaban
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	htmlx "golang.org/x/net/html"
+)
+
+func parseFixture(t *testing.T, name string) *htmlx.Node {
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	doc, err := htmlx.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParseExecutorPage(t *testing.T) {
+	executions, err := parseExecutorPage(parseFixture(t, "executor_running.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(executions) != 2 {
+		t.Fatalf("expected 2 executions but got %d", len(executions))
+	}
+
+	e := executions[0]
+	if e.ID != 1676218 || e.FlowID != "daily_load" || e.Project != "warehouse" || e.Status != "RUNNING" {
+		t.Errorf("unexpected execution %+v", e)
+	}
+	expectedStart := time.Date(2019, time.August, 21, 1, 53, 42, 0, ServerLocation)
+	if !e.StartTime.Time().Equal(expectedStart) {
+		t.Errorf("expected start time %s but got %s", expectedStart, e.StartTime.Time())
+	}
+
+	if executions[1].Status != "PAUSED" || executions[1].Project != "marketing" {
+		t.Errorf("unexpected execution %+v", executions[1])
+	}
+}
+
+func TestParseExecutorPageWithoutExecutions(t *testing.T) {
+	executions, err := parseExecutorPage(parseFixture(t, "executor_empty.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(executions) != 0 {
+		t.Errorf("expected no executions but got %v", executions)
+	}
+}
+
+func TestParseExecutorPageDetectsLoginPage(t *testing.T) {
+	_, err := parseExecutorPage(parseFixture(t, "login.html"))
+	if err != ErrInvalidSessionID {
+		t.Errorf("expected %v but got %v", ErrInvalidSessionID, err)
+	}
+}
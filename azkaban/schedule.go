@@ -0,0 +1,168 @@
+package azkaban
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes the fire times of a flow schedule.
+type Schedule interface {
+	// Next returns the first fire time after the given time, or the zero time if there is none.
+	Next(after time.Time) time.Time
+}
+
+// NextN returns up to n fire times of the given schedule after the given time.
+func NextN(s Schedule, after time.Time, n int) []time.Time {
+	var result []time.Time
+	t := after
+	for i := 0; i < n; i++ {
+		t = s.Next(t)
+		if t.IsZero() {
+			break
+		}
+		result = append(result, t)
+	}
+	return result
+}
+
+// PeriodUnit is the unit of a SchedulePeriod
+type PeriodUnit string
+
+const (
+	PeriodSeconds PeriodUnit = "second"
+	PeriodMinutes PeriodUnit = "minute"
+	PeriodHours   PeriodUnit = "hour"
+	PeriodDays    PeriodUnit = "day"
+	PeriodWeeks   PeriodUnit = "week"
+	PeriodMonths  PeriodUnit = "month"
+	PeriodYears   PeriodUnit = "year"
+)
+
+// SchedulePeriod is the recurrence of a non-cron schedule, e.g. every 1 day. Periods of days and longer follow the
+// calendar, so they are not fixed durations. The zero value means the schedule does not recur.
+type SchedulePeriod struct {
+	Count int
+	Unit  PeriodUnit
+}
+
+// ParseSchedulePeriod parses periods in the form Azkaban formats them, e.g. "1 day(s)" or "12 hour(s)". Azkaban
+// formats missing periods as "null", which parses as the zero value.
+func ParseSchedulePeriod(s string) (SchedulePeriod, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "null" || strings.EqualFold(s, "Not Recurring") {
+		return SchedulePeriod{}, nil
+	}
+
+	parts := strings.Fields(s)
+	if len(parts) != 2 {
+		return SchedulePeriod{}, fmt.Errorf("invalid schedule period %q", s)
+	}
+	count, err := strconv.Atoi(parts[0])
+	if err != nil || count < 0 {
+		return SchedulePeriod{}, fmt.Errorf("invalid count in schedule period %q", s)
+	}
+
+	unit := PeriodUnit(strings.TrimSuffix(strings.TrimSuffix(strings.ToLower(parts[1]), "(s)"), "s"))
+	switch unit {
+	case PeriodSeconds, PeriodMinutes, PeriodHours, PeriodDays, PeriodWeeks, PeriodMonths, PeriodYears:
+	default:
+		return SchedulePeriod{}, fmt.Errorf("invalid unit in schedule period %q", s)
+	}
+
+	return SchedulePeriod{Count: count, Unit: unit}, nil
+}
+
+// IsRecurring returns true if the period is non-zero.
+func (p SchedulePeriod) IsRecurring() bool {
+	return p.Count > 0
+}
+
+// AddTo returns t advanced by n periods.
+func (p SchedulePeriod) AddTo(t time.Time, n int) time.Time {
+	count := p.Count * n
+	switch p.Unit {
+	case PeriodSeconds:
+		return t.Add(time.Duration(count) * time.Second)
+	case PeriodMinutes:
+		return t.Add(time.Duration(count) * time.Minute)
+	case PeriodHours:
+		return t.Add(time.Duration(count) * time.Hour)
+	case PeriodDays:
+		return t.AddDate(0, 0, count)
+	case PeriodWeeks:
+		return t.AddDate(0, 0, 7*count)
+	case PeriodMonths:
+		return t.AddDate(0, count, 0)
+	case PeriodYears:
+		return t.AddDate(count, 0, 0)
+	}
+	return t
+}
+
+// approximate returns the approximate length of a period, used to avoid stepping through every period.
+func (p SchedulePeriod) approximate() time.Duration {
+	return p.AddTo(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC), 1).Sub(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func (p SchedulePeriod) String() string {
+	if !p.IsRecurring() {
+		return "not recurring"
+	}
+	if p.Count == 1 {
+		return fmt.Sprintf("every %s", p.Unit)
+	}
+	return fmt.Sprintf("every %d %ss", p.Count, p.Unit)
+}
+
+func (p *SchedulePeriod) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	if s == nil {
+		*p = SchedulePeriod{}
+		return nil
+	}
+
+	parsed, err := ParseSchedulePeriod(*s)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+	return nil
+}
+
+func (p SchedulePeriod) MarshalJSON() ([]byte, error) {
+	if !p.IsRecurring() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(fmt.Sprintf("%d %s(s)", p.Count, p.Unit))
+}
+
+// PeriodicSchedule fires at Start and then every Period.
+type PeriodicSchedule struct {
+	Start  time.Time
+	Period SchedulePeriod
+}
+
+func (s PeriodicSchedule) Next(after time.Time) time.Time {
+	if s.Start.After(after) {
+		return s.Start
+	}
+	if !s.Period.IsRecurring() {
+		return time.Time{}
+	}
+
+	// Estimate the number of periods since start, then correct for calendar irregularities
+	n := int(after.Sub(s.Start) / s.Period.approximate())
+	for n > 0 && s.Period.AddTo(s.Start, n).After(after) {
+		n--
+	}
+	for !s.Period.AddTo(s.Start, n).After(after) {
+		n++
+	}
+	return s.Period.AddTo(s.Start, n)
+}
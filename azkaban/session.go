@@ -0,0 +1,71 @@
+package azkaban
+
+import (
+	"context"
+	"fmt"
+)
+
+// Credentials are used to log into Azkaban
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// CredentialsProvider returns the credentials to log in with when a session expired.
+type CredentialsProvider func() (Credentials, error)
+
+// StaticCredentials returns a CredentialsProvider that always returns the given credentials.
+func StaticCredentials(username, password string) CredentialsProvider {
+	return func() (Credentials, error) {
+		return Credentials{Username: username, Password: password}, nil
+	}
+}
+
+func (c *Client) currentSessionID() string {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+	return c.SessionID
+}
+
+// withSession runs f and, if it failed because the session expired and the client has credentials, logs in again and
+// runs f once more.
+func (c *Client) withSession(ctx context.Context, f func() error) error {
+	sessionID := c.currentSessionID()
+	err := f()
+	if err != ErrSessionExpired || c.Credentials == nil {
+		return err
+	}
+
+	if err := c.renewSession(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return f()
+}
+
+// renewSession logs in again unless the given expired session was already replaced by a concurrent request.
+func (c *Client) renewSession(ctx context.Context, expiredSessionID string) error {
+	c.sessionMutex.Lock()
+	defer c.sessionMutex.Unlock()
+
+	if c.SessionID != expiredSessionID {
+		return nil
+	}
+
+	credentials, err := c.Credentials()
+	if err != nil {
+		return fmt.Errorf("session expired and could not get credentials to log in again: %s", err)
+	}
+
+	sessionID, err := login(ctx, c.http, c.url, credentials.Username, credentials.Password)
+	if err != nil {
+		return err
+	}
+	c.SessionID = sessionID
+
+	if c.OnSessionRenewed != nil {
+		c.OnSessionRenewed(sessionID)
+	}
+
+	return nil
+}
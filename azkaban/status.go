@@ -25,6 +25,8 @@ func (s Status) ColorFunc() func(string, ...interface{}) string {
 		colorFunc = color.MagentaString
 	case "PREPARING":
 		colorFunc = color.YellowString
+	case "PAUSED":
+		colorFunc = color.BlueString
 	default:
 		colorFunc = color.WhiteString
 	}
@@ -39,3 +41,12 @@ func (s Status) IsFailure() bool {
 func (s Status) IsSuccess() bool {
 	return s == "SUCCEEDED"
 }
+
+// IsFinished returns true for statuses executions and jobs don't change from anymore.
+func (s Status) IsFinished() bool {
+	switch s {
+	case "SUCCEEDED", "FAILED", "KILLED", "CANCELLED", "SKIPPED", "DISABLED", "FAILED_SUCCEEDED":
+		return true
+	}
+	return false
+}
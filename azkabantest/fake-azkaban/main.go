@@ -0,0 +1,66 @@
+// fake-azkaban runs the fake Azkaban server from package azkabantest with some sample projects for local development:
+//
+//	go run ./azkabantest/fake-azkaban -listen localhost:8081
+//	harbormaster login http://localhost:8081 azkaban azkaban
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/azkabantest"
+)
+
+func main() {
+	listen := flag.String("listen", "localhost:8081", "address to listen on")
+	flag.Parse()
+
+	s := azkabantest.NewUnstartedServer()
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.Listener.Close()
+	s.Listener = listener
+	addSampleData(s)
+	s.Start()
+	defer s.Close()
+
+	fmt.Printf("fake Azkaban listening on %s\n", s.URL)
+	fmt.Printf("log in with username %q and password %q, or use the session ID %s\n", s.Username, s.Password, azkabantest.DefaultSessionID)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	<-signals
+}
+
+func addSampleData(s *azkabantest.Server) {
+	now := time.Now()
+
+	s.AddProject("reporting")
+	s.AddFlow("reporting", "daily",
+		azkaban.FlowJob{ID: "extract", Type: "command"},
+		azkaban.FlowJob{ID: "transform", Type: "command", In: []string{"extract"}},
+		azkaban.FlowJob{ID: "load", Type: "command", In: []string{"transform"}},
+	)
+	s.AddFlow("reporting", "hourly", azkaban.FlowJob{ID: "refresh", Type: "command"})
+	for i := 5; i > 1; i-- {
+		s.AddExecution("reporting", "daily", "SUCCEEDED", now.Add(-time.Duration(i)*24*time.Hour))
+	}
+	failed := s.AddExecution("reporting", "daily", "FAILED", now.Add(-24*time.Hour))
+	failed.Job("load").Log = "loading into warehouse\nERROR: connection refused\nload failed\n"
+	s.AddExecution("reporting", "hourly", "SUCCEEDED", now.Add(-time.Hour))
+	s.AddExecution("reporting", "hourly", "RUNNING", now.Add(-5*time.Minute))
+	s.AddSchedule("reporting", "daily", "0 0 2 ? * *")
+	s.AddSchedule("reporting", "hourly", "0 0 * ? * *")
+
+	s.AddProject("ingest")
+	s.AddFlow("ingest", "events", azkaban.FlowJob{ID: "fetch", Type: "command"}, azkaban.FlowJob{ID: "store", Type: "command", In: []string{"fetch"}})
+	s.AddExecution("ingest", "events", "SUCCEEDED", now.Add(-2*time.Hour))
+}
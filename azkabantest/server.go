@@ -0,0 +1,1391 @@
+// Package azkabantest provides an in-process fake Azkaban server for tests and local development. It serves the
+// index, manager, executor, and schedule endpoints harbormaster uses, backed by projects, flows, executions, logs, and
+// schedules that are set up through the Server.
+package azkabantest
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+const (
+	DefaultUsername  = "azkaban"
+	DefaultPassword  = "azkaban"
+	DefaultSessionID = "azkabantest-session"
+)
+
+// Server is a fake Azkaban server. All setup methods are safe to call while the server is handling requests; values
+// returned by them must only be modified while no requests are in flight.
+type Server struct {
+	*httptest.Server
+
+	// Username and Password are the only credentials the server accepts
+	Username string
+	Password string
+
+	mutex          sync.Mutex
+	sessions       map[string]bool
+	nextSessionID  int
+	projects       []*Project
+	nextProjectID  int64
+	executions     map[int64]*Execution
+	nextExecID     int64
+	schedules      []*Schedule
+	nextScheduleID int64
+	failures       int
+	failureStatus  int
+	failureMatch   func(r *http.Request) bool
+}
+
+// Project is a project on the fake server
+type Project struct {
+	ID          int64
+	Name        string
+	Description string
+	Flows       []*Flow
+	// Versions are the uploaded zip files, oldest first
+	Versions []*ProjectVersion
+	// Denied makes listing the project's flows fail as if the user lacked the permission to read the project
+	Denied bool
+}
+
+// ProjectVersion is an uploaded version of a project
+type ProjectVersion struct {
+	Version    int
+	Zip        []byte
+	FileName   string
+	UploadUser string
+	UploadTime time.Time
+}
+
+// Flow is a flow with its jobs
+type Flow struct {
+	ID   string
+	Jobs []azkaban.FlowJob
+	// Properties are the properties of the jobs by job ID, without type and dependencies which fetchJobInfo adds from
+	// Jobs
+	Properties map[string]map[string]string
+}
+
+// Execution is an execution of a flow
+type Execution struct {
+	ID         int64
+	Project    *Project
+	Flow       *Flow
+	Status     azkaban.Status
+	SubmitTime time.Time
+	StartTime  time.Time
+	// EndTime is zero while the execution is running
+	EndTime time.Time
+	// UpdateTime is when the execution was last changed other than starting or ending, e.g. paused
+	UpdateTime time.Time
+	Jobs       []*JobExecution
+	Options    azkaban.ExecutionOptions
+}
+
+// Job returns the job execution with the given ID, or nested ID like embedded:job for jobs of embedded flows, or nil
+func (e *Execution) Job(id string) *JobExecution {
+	path := e.jobPath(id)
+	if path == nil {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// jobPath returns the embedded flows containing the job with the given nested ID followed by the job itself, or nil
+func (e *Execution) jobPath(nestedID string) []*JobExecution {
+	var path []*JobExecution
+	jobs := e.Jobs
+	for _, id := range strings.Split(nestedID, ":") {
+		var found *JobExecution
+		for _, j := range jobs {
+			if j.ID == id {
+				found = j
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		path = append(path, found)
+		jobs = found.Jobs
+	}
+	return path
+}
+
+// JobExecution is the execution of a single job within an execution
+type JobExecution struct {
+	ID        string
+	Type      string
+	In        []string
+	Status    azkaban.Status
+	StartTime time.Time
+	EndTime   time.Time
+	// UpdateTime is when the job was last changed other than starting or ending
+	UpdateTime time.Time
+	Attempt    int
+	Log        string
+	// Flow is the flow an embedded flow runs, with Type "flow" and its jobs in Jobs
+	Flow string
+	Jobs []*JobExecution
+}
+
+// updateTime is when Azkaban would have last changed the job, or any job of an embedded flow.
+func (j *JobExecution) updateTime() time.Time {
+	t := latest(j.StartTime, j.EndTime, j.UpdateTime)
+	for _, child := range j.Jobs {
+		t = latest(t, child.updateTime())
+	}
+	return t
+}
+
+// flowStatus returns the status of a flow with the given jobs: RUNNING until all jobs finished, then FAILED if any job
+// failed or SUCCEEDED otherwise.
+func flowStatus(jobs []*JobExecution) azkaban.Status {
+	status := azkaban.Status("SUCCEEDED")
+	for _, j := range jobs {
+		if !j.Status.IsFinished() {
+			return "RUNNING"
+		}
+		if j.Status.IsFailure() {
+			status = "FAILED"
+		}
+	}
+	return status
+}
+
+// updateTime is when Azkaban would have last changed the execution or any of its jobs.
+func (e *Execution) updateTime() time.Time {
+	t := latest(e.SubmitTime, e.StartTime, e.EndTime, e.UpdateTime)
+	for _, j := range e.Jobs {
+		t = latest(t, j.updateTime())
+	}
+	return t
+}
+
+func latest(times ...time.Time) time.Time {
+	var result time.Time
+	for _, t := range times {
+		if t.After(result) {
+			result = t
+		}
+	}
+	return result
+}
+
+// Schedule is a cron schedule of a flow
+type Schedule struct {
+	ID             int64
+	Project        *Project
+	Flow           *Flow
+	CronExpression string
+	SubmitUser     string
+	FirstSchedTime time.Time
+	Options        azkaban.ExecutionOptions
+}
+
+// NewServer starts a fake Azkaban server. Close it when done.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+	return s
+}
+
+// NewUnstartedServer returns a fake Azkaban server that is not started yet, e.g. to listen on a fixed address. Start
+// it with Start.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		Username:       DefaultUsername,
+		Password:       DefaultPassword,
+		sessions:       map[string]bool{DefaultSessionID: true},
+		nextProjectID:  1,
+		executions:     make(map[int64]*Execution),
+		nextExecID:     1,
+		nextScheduleID: 1,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/index", s.handleIndex)
+	mux.HandleFunc("/manager", s.handleManager)
+	mux.HandleFunc("/executor", s.handleExecutor)
+	mux.HandleFunc("/schedule", s.handleSchedule)
+	s.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.injectFailure(w, r) {
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+
+	return s
+}
+
+// Client returns a client connected to this server with a valid session.
+func (s *Server) Client() *azkaban.Client {
+	client, err := azkaban.ConnectWithSessionID(s.URL, DefaultSessionID)
+	if err != nil {
+		panic(err)
+	}
+	return client
+}
+
+// FailNext answers the next n requests with the given HTTP status, e.g. 503 to simulate a restarting web server.
+func (s *Server) FailNext(n int, statusCode int) {
+	s.FailNextMatching(n, statusCode, nil)
+}
+
+// FailNextMatching is like FailNext but only fails requests for which match returns true, all requests if match is
+// nil.
+func (s *Server) FailNextMatching(n int, statusCode int, match func(r *http.Request) bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.failures = n
+	s.failureStatus = statusCode
+	s.failureMatch = match
+}
+
+func (s *Server) injectFailure(w http.ResponseWriter, r *http.Request) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.failures == 0 || (s.failureMatch != nil && !s.failureMatch(r)) {
+		return false
+	}
+	s.failures--
+	http.Error(w, http.StatusText(s.failureStatus), s.failureStatus)
+	return true
+}
+
+// Project returns the project with the given name, or nil.
+func (s *Server) Project(name string) *Project {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.project(name)
+}
+
+// ExpireSessions invalidates all sessions, including DefaultSessionID.
+func (s *Server) ExpireSessions() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+// AddProject adds an empty project.
+func (s *Server) AddProject(name string) *Project {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := &Project{ID: s.nextProjectID, Name: name}
+	s.nextProjectID++
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// AddFlow adds a flow with the given jobs to the given project. Jobs depend on the jobs listed in their In field.
+func (s *Server) AddFlow(project string, flowID string, jobs ...azkaban.FlowJob) *Flow {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.project(project)
+	if p == nil {
+		panic(fmt.Sprintf("no project %s", project))
+	}
+	f := &Flow{ID: flowID, Jobs: jobs, Properties: make(map[string]map[string]string)}
+	p.Flows = append(p.Flows, f)
+	return f
+}
+
+// AddExecution adds an execution of the given flow with the given status that started at the given time. Jobs of
+// finished executions get the same status as the execution, except for failed executions where only the last job
+// failed and all others succeeded. Adjust the jobs of the returned execution as needed.
+func (s *Server) AddExecution(project string, flowID string, status azkaban.Status, start time.Time) *Execution {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.project(project)
+	if p == nil {
+		panic(fmt.Sprintf("no project %s", project))
+	}
+	f := p.flow(flowID)
+	if f == nil {
+		panic(fmt.Sprintf("no flow %s in project %s", flowID, project))
+	}
+
+	e := s.newExecution(p, f, azkaban.ExecutionOptions{})
+	e.Status = status
+	e.SubmitTime = start
+	e.StartTime = start
+	running := isRunning(status)
+	if !running {
+		e.EndTime = start.Add(time.Hour)
+	}
+	for i, j := range e.Jobs {
+		j.StartTime = start
+		switch {
+		case running:
+			j.Status = "RUNNING"
+		case status == "FAILED" && i < len(e.Jobs)-1:
+			j.Status = "SUCCEEDED"
+		default:
+			j.Status = status
+		}
+		if !running {
+			j.EndTime = e.EndTime
+		}
+	}
+
+	return e
+}
+
+// UpdateJob sets the status of a job of the given execution the way Azkaban does while running it. job is the ID of
+// the job, or its nested ID like embedded:job for jobs of embedded flows. Embedded flows and the execution run while
+// any of their jobs do, and finish once all jobs are, FAILED if any job failed.
+func (s *Server) UpdateJob(id int64, job string, status azkaban.Status) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	e := s.executions[id]
+	if e == nil || e.Job(job) == nil {
+		panic(fmt.Sprintf("no job %s in execution %d", job, id))
+	}
+	now := time.Now()
+	path := e.jobPath(job)
+	j := path[len(path)-1]
+	j.Status = status
+	j.UpdateTime = now
+	if status == "RUNNING" && j.StartTime.IsZero() {
+		j.StartTime = now
+	}
+	if status.IsFinished() {
+		j.EndTime = now
+	}
+
+	for i := len(path) - 2; i >= 0; i-- {
+		flow := path[i]
+		flow.Status = flowStatus(flow.Jobs)
+		flow.UpdateTime = now
+		if flow.StartTime.IsZero() {
+			flow.StartTime = now
+		}
+		if flow.Status.IsFinished() {
+			flow.EndTime = now
+		}
+	}
+	if status := flowStatus(e.Jobs); status.IsFinished() {
+		e.Status = status
+		e.EndTime = now
+	}
+}
+
+// Execution returns the execution with the given ID, or nil.
+func (s *Server) Execution(id int64) *Execution {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.executions[id]
+}
+
+// Schedules returns all schedules.
+func (s *Server) Schedules() []*Schedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*Schedule(nil), s.schedules...)
+}
+
+// AddSchedule schedules the given flow with a Quartz cron expression.
+func (s *Server) AddSchedule(project string, flowID string, cronExpression string) *Schedule {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	p := s.project(project)
+	if p == nil {
+		panic(fmt.Sprintf("no project %s", project))
+	}
+	f := p.flow(flowID)
+	if f == nil {
+		panic(fmt.Sprintf("no flow %s in project %s", flowID, project))
+	}
+	return s.schedule(p, f, cronExpression, azkaban.ExecutionOptions{})
+}
+
+func (s *Server) schedule(p *Project, f *Flow, cronExpression string, options azkaban.ExecutionOptions) *Schedule {
+	for i, existing := range s.schedules {
+		if existing.Project == p && existing.Flow == f {
+			s.schedules = append(s.schedules[:i], s.schedules[i+1:]...)
+			break
+		}
+	}
+
+	schedule := &Schedule{
+		ID:             s.nextScheduleID,
+		Project:        p,
+		Flow:           f,
+		CronExpression: cronExpression,
+		SubmitUser:     s.Username,
+		FirstSchedTime: time.Now(),
+		Options:        options,
+	}
+	s.nextScheduleID++
+	s.schedules = append(s.schedules, schedule)
+	return schedule
+}
+
+func (s *Server) newExecution(p *Project, f *Flow, options azkaban.ExecutionOptions) *Execution {
+	now := time.Now()
+	e := &Execution{
+		ID:         s.nextExecID,
+		Project:    p,
+		Flow:       f,
+		Status:     "RUNNING",
+		SubmitTime: now,
+		StartTime:  now,
+		Options:    options,
+	}
+	s.nextExecID++
+
+	disabled := make(map[string]bool)
+	for _, job := range options.DisabledJobs {
+		disabled[job] = true
+	}
+	for _, job := range f.Jobs {
+		status := azkaban.Status("READY")
+		if disabled[job.ID] {
+			status = "DISABLED"
+		}
+		e.Jobs = append(e.Jobs, &JobExecution{
+			ID:      job.ID,
+			Type:    job.Type,
+			In:      job.In,
+			Status:  status,
+			Attempt: 0,
+		})
+	}
+
+	s.executions[e.ID] = e
+	return e
+}
+
+func (s *Server) project(name string) *Project {
+	for _, p := range s.projects {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (s *Server) projectByID(id int64) *Project {
+	for _, p := range s.projects {
+		if p.ID == id {
+			return p
+		}
+	}
+	return nil
+}
+
+func (p *Project) flow(id string) *Flow {
+	for _, f := range p.Flows {
+		if f.ID == id {
+			return f
+		}
+	}
+	return nil
+}
+
+func isRunning(status azkaban.Status) bool {
+	switch status {
+	case "RUNNING", "PREPARING", "PAUSED", "READY":
+		return true
+	}
+	return false
+}
+
+func (s *Server) validSession(r *http.Request) bool {
+	return s.sessions[r.FormValue("session.id")]
+}
+
+// millis formats times the way Azkaban does in JSON responses, -1 for unset times.
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return -1
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, message string) {
+	writeJSON(w, map[string]interface{}{"error": message})
+}
+
+// handle checks the session and dispatches ajax requests to the matching handler while holding the server's lock.
+func (s *Server) handle(w http.ResponseWriter, r *http.Request, action string, handlers map[string]func(http.ResponseWriter, *http.Request)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.validSession(r) {
+		writeError(w, "session")
+		return
+	}
+
+	handler, ok := handlers[action]
+	if !ok {
+		writeError(w, fmt.Sprintf("Cannot execute command %s", action))
+		return
+	}
+	handler(w, r)
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" && r.URL.Path != "/index" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.FormValue("action") == "login" {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if r.FormValue("username") != s.Username || r.FormValue("password") != s.Password {
+			writeError(w, "Incorrect Login. Username/Password not found.")
+			return
+		}
+		s.nextSessionID++
+		sessionID := fmt.Sprintf("azkabantest-session-%d", s.nextSessionID)
+		s.sessions[sessionID] = true
+		writeJSON(w, map[string]interface{}{"status": "success", "session.id": sessionID})
+		return
+	}
+
+	s.handle(w, r, r.FormValue("ajax"), map[string]func(http.ResponseWriter, *http.Request){
+		"fetchallprojects": s.fetchAllProjects,
+	})
+}
+
+func (s *Server) handleManager(w http.ResponseWriter, r *http.Request) {
+	// Creating, deleting, and downloading projects are not ajax actions
+	action := r.FormValue("ajax")
+	if r.FormValue("action") == "create" {
+		action = "create"
+	} else if r.FormValue("delete") == "true" {
+		action = "delete"
+	} else if r.FormValue("download") == "true" {
+		action = "download"
+	}
+
+	s.handle(w, r, action, map[string]func(http.ResponseWriter, *http.Request){
+		"fetchprojectflows":   s.fetchProjectFlows,
+		"fetchflowgraph":      s.fetchFlowGraph,
+		"fetchFlowExecutions": s.fetchFlowExecutions,
+		"fetchJobInfo":        s.fetchJobInfo,
+		"create":              s.createProject,
+		"delete":              s.deleteProject,
+		"upload":              s.upload,
+		"download":            s.download,
+		"fetchProjectLogs":    s.fetchProjectLogs,
+	})
+}
+
+func (s *Server) handleExecutor(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("ajax") == "" {
+		s.executorPage(w, r)
+		return
+	}
+
+	s.handle(w, r, r.FormValue("ajax"), map[string]func(http.ResponseWriter, *http.Request){
+		"fetchexecflow":       s.fetchExecFlow,
+		"fetchexecflowupdate": s.fetchExecFlowUpdate,
+		"fetchExecJobLogs":    s.fetchExecJobLogs,
+		"executeFlow":         s.executeFlow,
+		"cancelFlow":          s.executionAction("KILLED"),
+		"pauseFlow":           s.executionAction("PAUSED"),
+		"resumeFlow":          s.executionAction("RUNNING"),
+		"getRunning":          s.getRunning,
+		"flowInfo":            s.flowInfo,
+	})
+}
+
+func (s *Server) handleSchedule(w http.ResponseWriter, r *http.Request) {
+	action := r.FormValue("ajax")
+	if action == "" {
+		action = r.FormValue("action")
+	}
+
+	s.handle(w, r, action, map[string]func(http.ResponseWriter, *http.Request){
+		"fetchSchedule":    s.fetchSchedule,
+		"scheduleCronFlow": s.scheduleCronFlow,
+		"removeSched":      s.removeSchedule,
+		"loadFlow":         s.loadSchedules,
+	})
+}
+
+func (s *Server) fetchAllProjects(w http.ResponseWriter, r *http.Request) {
+	projects := []map[string]interface{}{}
+	for _, p := range s.projects {
+		projects = append(projects, map[string]interface{}{
+			"projectId":   p.ID,
+			"projectName": p.Name,
+			"createdBy":   s.Username,
+		})
+	}
+	writeJSON(w, map[string]interface{}{"projects": projects})
+}
+
+// flowFromRequest looks up the project and flow given in the project and flow parameters and writes an error if
+// either doesn't exist.
+func (s *Server) flowFromRequest(w http.ResponseWriter, r *http.Request) (*Project, *Flow, bool) {
+	p := s.project(r.FormValue("project"))
+	if p == nil {
+		writeError(w, fmt.Sprintf("Project %s doesn't exist.", r.FormValue("project")))
+		return nil, nil, false
+	}
+	f := p.flow(r.FormValue("flow"))
+	if f == nil {
+		writeError(w, fmt.Sprintf("Flow %s not found.", r.FormValue("flow")))
+		return nil, nil, false
+	}
+	return p, f, true
+}
+
+func (s *Server) executionFromRequest(w http.ResponseWriter, r *http.Request) (*Execution, bool) {
+	id, _ := strconv.ParseInt(r.FormValue("execid"), 10, 64)
+	e, ok := s.executions[id]
+	if !ok {
+		writeError(w, fmt.Sprintf("Cannot find execution '%s'", r.FormValue("execid")))
+		return nil, false
+	}
+	return e, true
+}
+
+func (s *Server) fetchProjectFlows(w http.ResponseWriter, r *http.Request) {
+	p := s.project(r.FormValue("project"))
+	if p == nil {
+		writeError(w, fmt.Sprintf("Project %s doesn't exist.", r.FormValue("project")))
+		return
+	}
+	if p.Denied {
+		writeError(w, fmt.Sprintf("Permission denied. Need READ access to project %s.", p.Name))
+		return
+	}
+
+	flows := []map[string]interface{}{}
+	for _, f := range p.Flows {
+		flows = append(flows, map[string]interface{}{"flowId": f.ID})
+	}
+	writeJSON(w, map[string]interface{}{
+		"project":   p.Name,
+		"projectId": p.ID,
+		"flows":     flows,
+	})
+}
+
+func (s *Server) fetchFlowGraph(w http.ResponseWriter, r *http.Request) {
+	p, f, ok := s.flowFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	nodes := []map[string]interface{}{}
+	for _, job := range f.Jobs {
+		node := map[string]interface{}{
+			"id":   job.ID,
+			"type": job.Type,
+		}
+		if len(job.In) > 0 {
+			node["in"] = job.In
+		}
+		nodes = append(nodes, node)
+	}
+	writeJSON(w, map[string]interface{}{
+		"project":   p.Name,
+		"projectId": p.ID,
+		"flow":      f.ID,
+		"nodes":     nodes,
+	})
+}
+
+func (s *Server) fetchJobInfo(w http.ResponseWriter, r *http.Request) {
+	p := s.project(r.FormValue("project"))
+	if p == nil {
+		writeError(w, fmt.Sprintf("Project %s doesn't exist.", r.FormValue("project")))
+		return
+	}
+	f := p.flow(r.FormValue("flowName"))
+	if f == nil {
+		writeError(w, fmt.Sprintf("Flow %s not found.", r.FormValue("flowName")))
+		return
+	}
+	var job *azkaban.FlowJob
+	for i := range f.Jobs {
+		if f.Jobs[i].ID == r.FormValue("jobName") {
+			job = &f.Jobs[i]
+		}
+	}
+	if job == nil {
+		writeError(w, fmt.Sprintf("Job %s doesn't exist.", r.FormValue("jobName")))
+		return
+	}
+
+	params := map[string]string{"type": job.Type}
+	if len(job.In) > 0 {
+		params["dependencies"] = strings.Join(job.In, ",")
+	}
+	for k, v := range f.Properties[job.ID] {
+		params[k] = v
+	}
+	writeJSON(w, map[string]interface{}{
+		"jobName":        job.ID,
+		"jobType":        job.Type,
+		"generalParams":  params,
+		"overrideParams": map[string]string{},
+	})
+}
+
+func (s *Server) fetchFlowExecutions(w http.ResponseWriter, r *http.Request) {
+	p, f, ok := s.flowFromRequest(w, r)
+	if !ok {
+		return
+	}
+	start, _ := strconv.Atoi(r.FormValue("start"))
+	length, _ := strconv.Atoi(r.FormValue("length"))
+
+	var matching []*Execution
+	for _, e := range s.executions {
+		if e.Project == p && e.Flow == f {
+			matching = append(matching, e)
+		}
+	}
+	// Most recent first
+	sort.Slice(matching, func(i, j int) bool { return matching[i].ID > matching[j].ID })
+
+	executions := []map[string]interface{}{}
+	for i := start; i < len(matching) && i < start+length; i++ {
+		e := matching[i]
+		executions = append(executions, map[string]interface{}{
+			"execId":     e.ID,
+			"submitTime": millis(e.SubmitTime),
+			"startTime":  millis(e.StartTime),
+			"endTime":    millis(e.EndTime),
+			"status":     e.Status,
+			"projectId":  p.ID,
+			"flowId":     f.ID,
+			"submitUser": s.Username,
+		})
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"project":    p.Name,
+		"projectId":  p.ID,
+		"flow":       f.ID,
+		"from":       start,
+		"length":     length,
+		"total":      len(matching),
+		"executions": executions,
+	})
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request) {
+	name := r.FormValue("name")
+	if name == "" {
+		writeJSON(w, map[string]interface{}{"status": "error", "message": "Project name can't be empty."})
+		return
+	}
+	if s.project(name) != nil {
+		writeJSON(w, map[string]interface{}{"status": "error", "message": "Project already exists."})
+		return
+	}
+
+	s.projects = append(s.projects, &Project{ID: s.nextProjectID, Name: name, Description: r.FormValue("description")})
+	s.nextProjectID++
+	writeJSON(w, map[string]interface{}{"status": "success", "path": "manager?project=" + name, "action": "redirect"})
+}
+
+// deleteProject removes the project and redirects to the index page like Azkaban does.
+func (s *Server) deleteProject(w http.ResponseWriter, r *http.Request) {
+	for i, p := range s.projects {
+		if p.Name == r.FormValue("project") {
+			s.projects = append(s.projects[:i], s.projects[i+1:]...)
+			break
+		}
+	}
+	http.Redirect(w, r, "index", http.StatusFound)
+}
+
+// upload stores the uploaded zip as a new version of the project. Zips without any .job or .flow file are accepted
+// with a validation warning.
+func (s *Server) upload(w http.ResponseWriter, r *http.Request) {
+	p := s.project(r.FormValue("project"))
+	if p == nil {
+		writeError(w, fmt.Sprintf("Installation Failed. Project '%s' doesn't exist.", r.FormValue("project")))
+		return
+	}
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, "Missing file")
+		return
+	}
+	defer file.Close()
+	if header.Header.Get("Content-Type") != "application/zip" {
+		writeError(w, fmt.Sprintf("File type %s unrecognized.", header.Header.Get("Content-Type")))
+		return
+	}
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		writeError(w, err.Error())
+		return
+	}
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		writeError(w, fmt.Sprintf("Installation Failed. Error unzipping file. %s", err))
+		return
+	}
+
+	version := &ProjectVersion{
+		Version:    len(p.Versions) + 1,
+		Zip:        content,
+		FileName:   header.Filename,
+		UploadUser: s.Username,
+		UploadTime: time.Now(),
+	}
+	p.Versions = append(p.Versions, version)
+	response := map[string]interface{}{
+		"projectId": strconv.FormatInt(p.ID, 10),
+		"version":   strconv.Itoa(version.Version),
+	}
+	hasFlows := false
+	for _, f := range archive.File {
+		if strings.HasSuffix(f.Name, ".job") || strings.HasSuffix(f.Name, ".flow") {
+			hasFlows = true
+		}
+	}
+	if !hasFlows {
+		response["warn"] = "Uploaded project files. Validation warnings: \nNo flows found in project."
+	}
+	writeJSON(w, response)
+}
+
+func (s *Server) download(w http.ResponseWriter, r *http.Request) {
+	p := s.project(r.FormValue("project"))
+	if p == nil || len(p.Versions) == 0 {
+		http.Redirect(w, r, "index", http.StatusFound)
+		return
+	}
+	version := p.Versions[len(p.Versions)-1]
+	if r.FormValue("version") != "" {
+		n, _ := strconv.Atoi(r.FormValue("version"))
+		if n < 1 || n > len(p.Versions) {
+			http.Redirect(w, r, "index", http.StatusFound)
+			return
+		}
+		version = p.Versions[n-1]
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	// Like Azkaban, serve the zip with the name it was uploaded with
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", version.FileName))
+	w.Write(version.Zip)
+}
+
+// fetchProjectLogs logs the uploads of the project, the only events the server keeps.
+func (s *Server) fetchProjectLogs(w http.ResponseWriter, r *http.Request) {
+	p := s.project(r.FormValue("project"))
+	if p == nil {
+		writeError(w, fmt.Sprintf("Project %s doesn't exist.", r.FormValue("project")))
+		return
+	}
+
+	// Like Azkaban, return at most size events, 1000 by default, after skipping the most recent skip events
+	size, err := strconv.Atoi(r.FormValue("size"))
+	if err != nil {
+		size = 1000
+	}
+	skip, _ := strconv.Atoi(r.FormValue("skip"))
+	logData := [][]interface{}{}
+	for i := len(p.Versions) - 1 - skip; i >= 0 && len(logData) < size; i-- {
+		v := p.Versions[i]
+		logData = append(logData, []interface{}{v.UploadUser, millis(v.UploadTime), "UPLOADED", "Uploaded project files zip " + v.FileName})
+	}
+	writeJSON(w, map[string]interface{}{
+		"project":   p.Name,
+		"projectId": p.ID,
+		"columns":   []string{"user", "time", "type", "message"},
+		"logData":   logData,
+	})
+}
+
+func (s *Server) fetchExecFlow(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.executionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"execid":     e.ID,
+		"project":    e.Project.Name,
+		"projectId":  e.Project.ID,
+		"flow":       e.Flow.ID,
+		"flowId":     e.Flow.ID,
+		"id":         e.Flow.ID,
+		"status":     e.Status,
+		"submitTime": millis(e.SubmitTime),
+		"startTime":  millis(e.StartTime),
+		"endTime":    millis(e.EndTime),
+		"updateTime": millis(e.updateTime()),
+		"attempt":    0,
+		"nodes":      jobNodes(e.Jobs, "", -1, true),
+	})
+}
+
+// fetchExecFlowUpdate writes the jobs changed after lastUpdateTime.
+func (s *Server) fetchExecFlowUpdate(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.executionFromRequest(w, r)
+	if !ok {
+		return
+	}
+	lastUpdateTime, _ := strconv.ParseInt(r.FormValue("lastUpdateTime"), 10, 64)
+
+	writeJSON(w, map[string]interface{}{
+		"id":         e.Flow.ID,
+		"status":     e.Status,
+		"startTime":  millis(e.StartTime),
+		"endTime":    millis(e.EndTime),
+		"updateTime": millis(e.updateTime()),
+		"nodes":      jobNodes(e.Jobs, "", lastUpdateTime, false),
+	})
+}
+
+// jobNodes returns the jobs changed after the given time in milliseconds, or all jobs if it is negative, with the jobs
+// of embedded flows nested. Only full nodes have the type and dependencies, updates leave them out.
+func jobNodes(jobs []*JobExecution, parentID string, since int64, full bool) []map[string]interface{} {
+	nodes := []map[string]interface{}{}
+	for _, j := range jobs {
+		if since >= 0 && millis(j.updateTime()) <= since {
+			continue
+		}
+		nestedID := j.ID
+		if parentID != "" {
+			nestedID = parentID + ":" + j.ID
+		}
+		node := map[string]interface{}{
+			"id":         j.ID,
+			"nestedId":   nestedID,
+			"status":     j.Status,
+			"startTime":  millis(j.StartTime),
+			"endTime":    millis(j.EndTime),
+			"updateTime": millis(j.updateTime()),
+			"attempt":    j.Attempt,
+		}
+		if full {
+			node["type"] = j.Type
+			if len(j.In) > 0 {
+				node["in"] = j.In
+			}
+		}
+		if len(j.Jobs) > 0 {
+			node["flow"] = j.Flow
+			node["nodes"] = jobNodes(j.Jobs, nestedID, since, full)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func (s *Server) fetchExecJobLogs(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.executionFromRequest(w, r)
+	if !ok {
+		return
+	}
+	j := e.Job(r.FormValue("jobId"))
+	if j == nil {
+		writeError(w, fmt.Sprintf("Job %s doesn't exist in %d", r.FormValue("jobId"), e.ID))
+		return
+	}
+
+	offset, _ := strconv.Atoi(r.FormValue("offset"))
+	length, _ := strconv.Atoi(r.FormValue("length"))
+	if offset > len(j.Log) {
+		offset = len(j.Log)
+	}
+	end := offset + length
+	if end > len(j.Log) {
+		end = len(j.Log)
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"data":   template.HTMLEscapeString(j.Log[offset:end]),
+		"offset": offset,
+		"length": end - offset,
+	})
+}
+
+func (s *Server) executeFlow(w http.ResponseWriter, r *http.Request) {
+	p, f, ok := s.flowFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	e := s.newExecution(p, f, optionsFromRequest(r))
+	writeJSON(w, map[string]interface{}{
+		"project": p.Name,
+		"flow":    f.ID,
+		"execid":  e.ID,
+		"message": fmt.Sprintf("Execution submitted successfully with exec id %d", e.ID),
+	})
+}
+
+func (s *Server) executionAction(status azkaban.Status) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		e, ok := s.executionFromRequest(w, r)
+		if !ok {
+			return
+		}
+		if !isRunning(e.Status) {
+			writeError(w, fmt.Sprintf("Execution %d is not running.", e.ID))
+			return
+		}
+
+		e.Status = status
+		e.UpdateTime = time.Now()
+		if status == "KILLED" {
+			e.EndTime = time.Now()
+			for _, j := range e.Jobs {
+				if isRunning(j.Status) {
+					j.Status = "KILLED"
+					j.EndTime = e.EndTime
+				}
+			}
+		}
+		writeJSON(w, map[string]interface{}{})
+	}
+}
+
+func (s *Server) getRunning(w http.ResponseWriter, r *http.Request) {
+	p, f, ok := s.flowFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	ids := []int64{}
+	for _, e := range s.executions {
+		if e.Project == p && e.Flow == f && isRunning(e.Status) {
+			ids = append(ids, e.ID)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	writeJSON(w, map[string]interface{}{"execIds": ids})
+}
+
+func (s *Server) flowInfo(w http.ResponseWriter, r *http.Request) {
+	e, ok := s.executionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	nodeStatus := make(map[string]azkaban.Status)
+	for _, j := range e.Jobs {
+		nodeStatus[j.ID] = j.Status
+	}
+	disabled := nestDisabledJobs(e.Options.DisabledJobs)
+	flowParam := map[string]string{}
+	for k, v := range e.Options.FlowParameters {
+		flowParam[k] = v
+	}
+	// Azkaban reports the name of its enum rather than what executeFlow accepts
+	failureAction := map[azkaban.FailureAction]string{
+		azkaban.FinishCurrent:     "FINISH_CURRENTLY_RUNNING",
+		azkaban.CancelImmediately: "CANCEL_ALL",
+		azkaban.FinishPossible:    "FINISH_ALL_POSSIBLE",
+	}[e.Options.FailureAction]
+	if failureAction == "" {
+		failureAction = "FINISH_CURRENTLY_RUNNING"
+	}
+	concurrentOption := e.Options.ConcurrentOption
+	if concurrentOption == "" {
+		concurrentOption = azkaban.ConcurrentIgnore
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"execid":                e.ID,
+		"flowParam":             flowParam,
+		"successEmails":         append([]string{}, e.Options.SuccessEmails...),
+		"failureEmails":         append([]string{}, e.Options.FailureEmails...),
+		"successEmailsOverride": len(e.Options.SuccessEmails) > 0,
+		"failureEmailsOverride": len(e.Options.FailureEmails) > 0,
+		"notifyFailureFirst":    e.Options.NotifyFailureFirst,
+		"notifyFailureLast":     e.Options.NotifyFailureLast,
+		"failureAction":         failureAction,
+		"concurrentOptions":     concurrentOption,
+		"pipelineLevel":         e.Options.PipelineLevel,
+		"nodeStatus":            nodeStatus,
+		"disabled":              disabled,
+	})
+}
+
+// nestDisabledJobs converts nested job IDs to the disabled jobs the way Azkaban reports them: names for jobs of the
+// flow, objects with id and children for jobs of embedded flows.
+func nestDisabledJobs(ids []string) []interface{} {
+	disabled := []interface{}{}
+	var flows []string
+	children := make(map[string][]string)
+	for _, id := range ids {
+		parts := strings.SplitN(id, ":", 2)
+		if len(parts) == 1 {
+			disabled = append(disabled, id)
+			continue
+		}
+		if _, ok := children[parts[0]]; !ok {
+			flows = append(flows, parts[0])
+		}
+		children[parts[0]] = append(children[parts[0]], parts[1])
+	}
+	for _, flow := range flows {
+		disabled = append(disabled, map[string]interface{}{"id": flow, "children": nestDisabledJobs(children[flow])})
+	}
+	return disabled
+}
+
+// flattenDisabledJobs returns the nested IDs of disabled jobs as executeFlow accepts them, see nestDisabledJobs.
+func flattenDisabledJobs(disabled []interface{}, prefix string) []string {
+	var ids []string
+	for _, d := range disabled {
+		switch d := d.(type) {
+		case string:
+			ids = append(ids, prefix+d)
+		case map[string]interface{}:
+			id, _ := d["id"].(string)
+			children, _ := d["children"].([]interface{})
+			ids = append(ids, flattenDisabledJobs(children, prefix+id+":")...)
+		}
+	}
+	return ids
+}
+
+// optionsFromRequest reads execution options the way Azkaban does
+func optionsFromRequest(r *http.Request) azkaban.ExecutionOptions {
+	r.ParseForm()
+	options := azkaban.ExecutionOptions{
+		ConcurrentOption:   azkaban.ConcurrentOption(r.FormValue("concurrentOption")),
+		NotifyFailureFirst: r.FormValue("notifyFailureFirst") == "true",
+		NotifyFailureLast:  r.FormValue("notifyFailureLast") == "true",
+	}
+	options.PipelineLevel, _ = strconv.Atoi(r.FormValue("pipelineLevel"))
+
+	for key, values := range r.Form {
+		if strings.HasPrefix(key, "flowOverride[") && strings.HasSuffix(key, "]") {
+			if options.FlowParameters == nil {
+				options.FlowParameters = make(map[string]string)
+			}
+			options.FlowParameters[key[len("flowOverride["):len(key)-1]] = values[0]
+		}
+	}
+
+	// Like Azkaban, ignore failure actions it doesn't know and run with the default instead
+	switch action := azkaban.FailureAction(r.FormValue("failureAction")); action {
+	case azkaban.FinishCurrent, azkaban.CancelImmediately, azkaban.FinishPossible:
+		options.FailureAction = action
+	}
+
+	if disabled := r.FormValue("disabled"); disabled != "" {
+		var entries []interface{}
+		json.Unmarshal([]byte(disabled), &entries)
+		options.DisabledJobs = flattenDisabledJobs(entries, "")
+	}
+	if emails := r.FormValue("successEmails"); emails != "" && r.FormValue("successEmailsOverride") == "true" {
+		options.SuccessEmails = strings.Split(emails, ",")
+	}
+	if emails := r.FormValue("failureEmails"); emails != "" && r.FormValue("failureEmailsOverride") == "true" {
+		options.FailureEmails = strings.Split(emails, ",")
+	}
+
+	return options
+}
+
+func (s *Server) fetchSchedule(w http.ResponseWriter, r *http.Request) {
+	projectID, _ := strconv.ParseInt(r.FormValue("projectId"), 10, 64)
+	for _, schedule := range s.schedules {
+		if schedule.Project.ID != projectID || schedule.Flow.ID != r.FormValue("flowId") {
+			continue
+		}
+
+		cron, err := azkaban.ParseCronExpression(schedule.CronExpression)
+		if err != nil {
+			writeError(w, err.Error())
+			return
+		}
+		format := "2006-01-02 15:04:05"
+		writeJSON(w, map[string]interface{}{
+			"schedule": map[string]interface{}{
+				"scheduleId":     strconv.FormatInt(schedule.ID, 10),
+				"submitUser":     schedule.SubmitUser,
+				"firstSchedTime": schedule.FirstSchedTime.In(azkaban.ServerLocation).Format(format),
+				"nextExecTime":   cron.Next(time.Now().In(azkaban.ServerLocation)).Format(format),
+				"period":         "null",
+				"cronExpression": schedule.CronExpression,
+			},
+		})
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{})
+}
+
+func (s *Server) scheduleCronFlow(w http.ResponseWriter, r *http.Request) {
+	p := s.project(r.FormValue("projectName"))
+	if p == nil {
+		writeJSON(w, map[string]interface{}{"status": "error", "message": fmt.Sprintf("Project %s doesn't exist.", r.FormValue("projectName"))})
+		return
+	}
+	f := p.flow(r.FormValue("flow"))
+	if f == nil {
+		writeJSON(w, map[string]interface{}{"status": "error", "message": fmt.Sprintf("Flow %s cannot be found in project %s", r.FormValue("flow"), p.Name)})
+		return
+	}
+	if _, err := azkaban.ParseCronExpression(r.FormValue("cronExpression")); err != nil {
+		writeError(w, fmt.Sprintf("This expression <%s> can not be parsed to quartz cron.", r.FormValue("cronExpression")))
+		return
+	}
+
+	schedule := s.schedule(p, f, r.FormValue("cronExpression"), optionsFromRequest(r))
+	writeJSON(w, map[string]interface{}{
+		"status":     "success",
+		"message":    fmt.Sprintf("%s.%s scheduled.", p.Name, f.ID),
+		"scheduleId": schedule.ID,
+	})
+}
+
+func (s *Server) removeSchedule(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseInt(r.FormValue("scheduleId"), 10, 64)
+	for i, schedule := range s.schedules {
+		if schedule.ID == id {
+			s.schedules = append(s.schedules[:i], s.schedules[i+1:]...)
+			writeJSON(w, map[string]interface{}{
+				"status":  "success",
+				"message": fmt.Sprintf("flow %s removed from Schedules.", schedule.Flow.ID),
+			})
+			return
+		}
+	}
+
+	writeJSON(w, map[string]interface{}{"status": "error", "message": fmt.Sprintf("Schedule with ID %d does not exist", id)})
+}
+
+func (s *Server) loadSchedules(w http.ResponseWriter, r *http.Request) {
+	items := []map[string]interface{}{}
+	for _, schedule := range s.schedules {
+		items = append(items, map[string]interface{}{
+			"scheduleid":  schedule.ID,
+			"flowname":    schedule.Flow.ID,
+			"projectname": schedule.Project.Name,
+			"time":        millis(schedule.FirstSchedTime),
+			"cron":        schedule.CronExpression,
+			"period":      0,
+			"history":     false,
+		})
+	}
+	writeJSON(w, map[string]interface{}{"items": items})
+}
+
+var executorPageTemplate = template.Must(template.New("executor").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head><title>Azkaban Web Client</title></head>
+<body>
+<table id="executingJobs" class="table table-striped table-bordered table-hover table-condensed executions-table">
+  <thead>
+  <tr>
+    <th>#</th>
+    <th class="execid">Execution Id</th>
+    <th>Executor</th>
+    <th>Flow</th>
+    <th>Project</th>
+    <th class="user">User</th>
+    <th class="date">Start Time</th>
+    <th class="date">End Time</th>
+    <th class="status">Status</th>
+  </tr>
+  </thead>
+  <tbody>
+  {{- range $i, $e := .}}
+  <tr>
+    <td>{{$i}}</td>
+    <td><a href="/executor?execid={{$e.ID}}">{{$e.ID}}</a></td>
+    <td>localhost:12321</td>
+    <td><a href="/manager?project={{$e.Project}}&flow={{$e.Flow}}">{{$e.Flow}}</a></td>
+    <td><a href="/manager?project={{$e.Project}}">{{$e.Project}}</a></td>
+    <td>azkaban</td>
+    <td>{{$e.StartTime}}</td>
+    <td>-</td>
+    <td><div class="status {{$e.Status}}">{{$e.Status}}</div></td>
+  </tr>
+  {{- else}}
+  <tr><td colspan="9">No Executing Flows</td></tr>
+  {{- end}}
+  </tbody>
+</table>
+</body>
+</html>
+`))
+
+var loginPage = `<!DOCTYPE html>
+<html lang="en">
+<head><title>Azkaban Web Client</title></head>
+<body>
+<form id="login-form" method="post" action="">
+  <input type="text" id="username" name="username">
+  <input type="password" id="password" name="password">
+</form>
+</body>
+</html>
+`
+
+func (s *Server) executorPage(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	w.Header().Set("Content-Type", "text/html")
+	if !s.validSession(r) {
+		fmt.Fprint(w, loginPage)
+		return
+	}
+
+	type row struct {
+		ID        int64
+		Project   string
+		Flow      string
+		StartTime string
+		Status    azkaban.Status
+	}
+	rows := []row{}
+	var ids []int64
+	for id, e := range s.executions {
+		if isRunning(e.Status) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		e := s.executions[id]
+		rows = append(rows, row{
+			ID:        e.ID,
+			Project:   e.Project.Name,
+			Flow:      e.Flow.ID,
+			StartTime: e.StartTime.In(azkaban.ServerLocation).Format("2006-01-02 15:04:05"),
+			Status:    e.Status,
+		})
+	}
+
+	executorPageTemplate.Execute(w, rows)
+}
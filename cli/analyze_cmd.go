@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/format"
+	"github.com/spf13/cobra"
+)
+
+func NewAnalyzeCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze <execid|execution url>",
+		Short: "show which jobs of an execution determined how long it took",
+		Long: `Shows the critical path of an execution, the chain of jobs it had to wait for,
+and for every job when it started, how long it ran, how long it waited for
+Azkaban after its dependencies finished, and its slack: how much longer it could
+have taken without delaying the execution. Jobs with slack didn't make the
+execution slower, the longest jobs on the critical path did:
+
+# harbormaster analyze 12345
+# harbormaster analyze 12345 -o csv > timings.csv`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			executionID, err := parseExecutionID(args[0])
+			if err != nil {
+				fatal(err)
+			}
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			width, _ := cmd.Flags().GetInt("width")
+
+			client := context.Client()
+			status, err := client.FlowExecutionStatus(executionID)
+			if err != nil {
+				fatal(err)
+			}
+			// The flow may have been deleted since, the execution's own dependencies are still good enough then
+			graph, err := client.FlowJobList(status.Project, status.FlowID)
+			if err != nil && !azkaban.IsNotFound(err) {
+				fatal(err)
+			}
+
+			analysis := azkaban.AnalyzeExecution(status, graph.Nodes)
+			views := []jobAnalysisView{}
+			for _, j := range analysis.Jobs {
+				views = append(views, newJobAnalysisView(j))
+			}
+			err = out.write(views, analysis, func(w io.Writer) { printAnalysis(w, analysis, width) })
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+
+	addOutputFlags(cmd)
+	cmd.Flags().Int("width", 60, "width of the bars of the Gantt chart")
+
+	return cmd
+}
+
+// printAnalysis prints the critical path, the jobs that delayed the execution most, and a Gantt chart of all jobs.
+func printAnalysis(writer io.Writer, analysis azkaban.ExecutionAnalysis, width int) {
+	status := analysis.Status
+	fmt.Fprintf(
+		writer,
+		"Execution %d of %s %s: %s %s\n",
+		status.ExecutionID,
+		status.Project,
+		status.FlowID,
+		status.Status.Colored(),
+		format.DurationHumanReadable(analysis.Duration),
+	)
+	if len(analysis.Jobs) == 0 {
+		fmt.Fprintln(writer, "no jobs started yet")
+		return
+	}
+
+	var path []string
+	for _, j := range analysis.CriticalPath {
+		path = append(path, j.QualifiedID())
+	}
+	fmt.Fprintf(writer, "Critical path: %s\n", strings.Join(path, " -> "))
+
+	fmt.Fprintln(writer, "Slowest jobs on the critical path:")
+	for _, j := range analysis.Bottlenecks(5) {
+		fmt.Fprintf(writer, "  %s %s (%s)\n", j.Job.QualifiedID(), format.DurationHumanReadable(j.Duration), share(j.Duration, analysis.Duration))
+	}
+	fmt.Fprintln(writer)
+
+	w := new(tabwriter.Writer)
+	w.Init(writer, 4, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", "Job", "Start", "Duration", "Wait", "Slack", "")
+	for _, j := range analysis.Jobs {
+		fmt.Fprintf(
+			w,
+			"%s \t+%s \t%s \t%s \t%s \t|%s|\n",
+			j.Job.QualifiedID(),
+			shortDuration(j.Start),
+			shortDuration(j.Duration),
+			shortDuration(j.Wait),
+			shortDuration(j.Slack),
+			ganttBar(j, analysis.Duration, width),
+		)
+	}
+	w.Flush()
+}
+
+// ganttBar draws when the job ran within an execution that took total: # for jobs on the critical path, = for the
+// others. Every job that ran gets at least one character.
+func ganttBar(j azkaban.JobAnalysis, total time.Duration, width int) string {
+	if total <= 0 || width <= 0 {
+		return ""
+	}
+	from := int(int64(j.Start) * int64(width) / int64(total))
+	to := int(int64(j.Start+j.Duration) * int64(width) / int64(total))
+	if from >= width {
+		from = width - 1
+	}
+	if to > width {
+		to = width
+	}
+	if to <= from {
+		to = from + 1
+	}
+
+	bar := "="
+	if j.Critical {
+		bar = "#"
+	}
+	return strings.Repeat(" ", from) + strings.Repeat(bar, to-from) + strings.Repeat(" ", width-to)
+}
+
+// shortDuration is format.DurationHumanReadable, but shows 0s rather than nothing for durations under a second.
+func shortDuration(d time.Duration) string {
+	if s := format.DurationHumanReadable(d); s != "" {
+		return s
+	}
+	return "0s"
+}
+
+// share formats d as a percentage of total.
+func share(d time.Duration, total time.Duration) string {
+	if total <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%d%%", int64(d)*100/int64(total))
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/spf13/cobra"
+)
+
+func NewCancelCmd(context Context) *cobra.Command {
+	return newExecutionActionCmd(context, executionAction{
+		use:   "cancel [execid|execution url]...",
+		short: "cancel running executions",
+		long: `Cancels one or more running executions. Executions can be given either as
+execution IDs or as execution URLs copied from the Azkaban web ui:
+
+# harbormaster cancel 12345 12346
+# harbormaster cancel '<azkaban url>/executor?execid=12345'
+
+With --flow all running executions of flows in the current project matching the
+given prefix or /regex/ are cancelled:
+
+# harbormaster -p <project> cancel --flow /^backfill_/`,
+		verb:    "cancelled",
+		perform: (*azkaban.Client).CancelExecution,
+	})
+}
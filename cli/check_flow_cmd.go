@@ -10,40 +10,59 @@ import (
 	"log"
 	"os"
 	"strings"
+	"time"
 )
 
 func newCheckFlowCmd(context Context) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "flow",
 		Aliases: []string{"f"},
 		Short:   "check a given flow",
 		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
 			flowRepo := context.Context().Flows()
 			flow, proj, err := flowRepo.Flow(azkaban.Project{Name: context.Project()}, args[0])
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 			statusChecker := FlowStatusChecker{
 				client:         context.Client(),
 				context:        context.Context(),
 				histogramCount: 6,
+				upcomingCount:  5,
 				project:        proj,
 				flow:           flow,
 			}
 
+			// Machine readable output only reports the status, the interactive actions need a terminal
+			if !out.isTable() {
+				check, err := statusChecker.check()
+				if err != nil {
+					fatal(err)
+				}
+				if err := out.write(check.view(statusChecker.upcomingCount), check, nil); err != nil {
+					fatal(err)
+				}
+				return
+			}
+
 			status, err := statusChecker.printFlowStatus()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
 			if status.Health == azkaban.Critical {
-				fmt.Printf("Execution failed in %q, log messages of interest:\n", status.FailedJob.ID)
+				fmt.Printf("Execution failed in %q, log messages of interest:\n", status.FailedJob.QualifiedID())
 				client := context.Client()
 				buffer := bytes.NewBuffer([]byte{})
-				_, err := client.FetchLogsUntilEnd(status.LastExecution.ID, status.FailedJob.ID, 0, buffer)
+				_, err := client.FetchLogsUntilEnd(status.LastExecution.ID, status.FailedJob.QualifiedID(), 0, buffer)
 				if err != nil {
-					log.Fatal(err)
+					fatal(err)
 				}
 
 				scanner := bufio.NewScanner(strings.NewReader(buffer.String()))
@@ -82,21 +101,27 @@ func newCheckFlowCmd(context Context) *cobra.Command {
 					input := strings.ToLower(strings.TrimSpace(scanner.Text()))
 
 					if input == "restart" {
-						client.RestartFlowNow(proj.Name, flow.FlowID)
+						result, err := client.RetryFailedJobs(status.LastExecution.ID)
+						if err != nil {
+							fatal(err)
+						}
+						fmt.Printf("submitted execution %d retrying failed jobs of %d\n", result.ExecutionID, status.LastExecution.ID)
 					} else if input == "logs" {
 						// TODO this might be slow:
 						// fmt.Println(l)
-						_, err = client.FetchLogsUntilEnd(status.LastExecution.ID, status.FailedJob.ID, 0, os.Stdout)
+						_, err = client.FetchLogsUntilEnd(status.LastExecution.ID, status.FailedJob.QualifiedID(), 0, os.Stdout)
 						if err != nil {
-							log.Fatal(err)
+							fatal(err)
 						}
 					} else if input == "status" {
 						status, err = statusChecker.printFlowStatus()
 						if err != nil {
-							log.Fatal(err)
+							fatal(err)
 						}
 					} else if input == "unschedule" {
-						fmt.Println("not implemented yet")
+						if err := unscheduleFlow(client, proj, flow); err != nil {
+							fatal(err)
+						}
 					} else {
 						run = false
 					}
@@ -104,12 +129,17 @@ func newCheckFlowCmd(context Context) *cobra.Command {
 			}
 		},
 	}
+
+	addOutputFlags(cmd)
+
+	return cmd
 }
 
 type FlowStatusChecker struct {
 	client         *azkaban.Client
 	context        *azkaban.Context
 	histogramCount uint
+	upcomingCount  int
 	project        azkaban.Project
 	flow           azkaban.Flow
 }
@@ -125,6 +155,19 @@ func (h FlowStatusChecker) printSchedule() error {
 		scheduledMessage = fmt.Sprintf("%s", humanize.Time(schedule.NextExecTime.Time()))
 	}
 	fmt.Printf("%-16s %s\n", "Next execution:", scheduledMessage)
+	if schedule.IsScheduled() {
+		fmt.Printf("%-16s %s\n", "Schedule:", schedule.Description())
+		if err := schedule.CronExpression.Err(); err != nil {
+			fmt.Printf("%-16s %s\n", "Upcoming:", "unavailable, "+err.Error())
+		}
+		for i, t := range schedule.NextExecutions(h.upcomingCount) {
+			label := ""
+			if i == 0 {
+				label = "Upcoming:"
+			}
+			fmt.Printf("%-16s %s\n", label, t.Local().Format(time.RFC1123))
+		}
+	}
 	return nil
 }
 
@@ -166,22 +209,91 @@ func (h FlowStatusChecker) printFlowStatus() (status FlowStatus, err error) {
 		fmt.Println()
 
 		status.LastExecution = executions.MostRecentExecution()
-		executionID := executions.MostRecentExecution().ID
-
-		flowExecStatus, err := client.FlowExecutionStatus(executionID)
+		status.FailedJob, err = h.failedJob(status.LastExecution.ID)
 		if err != nil {
 			return status, err
 		}
+	}
 
-		for _, n := range flowExecStatus.Nodes {
-			if n.Status.IsFailure() {
-				status.FailedJob = n
-				break
-			}
+	return status, nil
+}
+
+// flowCheck is the status of a flow as collected by check, the value go-templates see
+type flowCheck struct {
+	Project azkaban.Project
+	Flow    azkaban.Flow
+	// Executions are the most recent executions, most recent first
+	Executions azkaban.Executions
+	Schedule   azkaban.FlowSchedule
+	// LastExecution is the status of the most recent execution, nil if the flow never ran
+	LastExecution *azkaban.FlowExecutionStatus
+}
+
+// Health returns the health of the flow based on its recent executions
+func (c flowCheck) Health() azkaban.Health {
+	return c.Executions.Health()
+}
+
+// FailedJob returns the first failed job of the most recent execution, or nil. Failures in embedded flows are
+// reported as the failed job of the embedded flow rather than the flow.
+func (c flowCheck) FailedJob() *azkaban.JobStatus {
+	if c.LastExecution == nil {
+		return nil
+	}
+	if failed := c.LastExecution.FailedJobs(); len(failed) > 0 {
+		return &failed[0]
+	}
+	return nil
+}
+
+func (c flowCheck) view(upcoming int) flowStatusView {
+	view := flowStatusView{
+		flowHealthView: newFlowHealthView(c.Project.Name, c.Flow.FlowID, c.Executions),
+		Schedule:       newScheduleView(c.Schedule, upcoming),
+	}
+	if failedJob := c.FailedJob(); failedJob != nil && c.Health() == azkaban.Critical {
+		view.FailedJob = failedJob.QualifiedID()
+	}
+	return view
+}
+
+// check collects the status of the flow like printFlowStatus, without printing it
+func (h FlowStatusChecker) check() (flowCheck, error) {
+	check := flowCheck{Project: h.project, Flow: h.flow}
+
+	var err error
+	check.Executions, err = h.client.FlowExecutions(h.project.Name, h.flow.FlowID, azkaban.TenMostRecent)
+	if err != nil {
+		return check, err
+	}
+
+	check.Schedule, err = h.client.FlowSchedule(h.project.ID, h.flow.FlowID)
+	if err != nil {
+		return check, err
+	}
+
+	if len(check.Executions) > 0 {
+		status, err := h.client.FlowExecutionStatus(check.Executions.MostRecentExecution().ID)
+		if err != nil {
+			return check, err
 		}
+		check.LastExecution = &status
 	}
 
-	return status, nil
+	return check, nil
+}
+
+// failedJob returns the first failed job of the given execution, within embedded flows if they failed
+func (h FlowStatusChecker) failedJob(executionID int64) (azkaban.JobStatus, error) {
+	flowExecStatus, err := h.client.FlowExecutionStatus(executionID)
+	if err != nil {
+		return azkaban.JobStatus{}, err
+	}
+
+	if failed := flowExecStatus.FailedJobs(); len(failed) > 0 {
+		return failed[0], nil
+	}
+	return azkaban.JobStatus{}, nil
 }
 
 func printActionTerm() {
@@ -6,9 +6,9 @@ import (
 	"github.com/fatih/color"
 	"github.com/ilikeorangutans/harbormaster/azkaban"
 	"github.com/spf13/cobra"
-	"log"
-	"os"
+	"io"
 	"strings"
+	"sync"
 	"text/tabwriter"
 	"time"
 )
@@ -21,35 +21,25 @@ func newCheckProjectCmd(context Context) *cobra.Command {
 		Short:   "check flows of a project",
 
 		Run: func(cmd *cobra.Command, args []string) {
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
 
-			s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-			spinnerStatus := newSpinnerStatus()
-			s.Start()
-			s.Suffix = spinnerStatus.String()
+			spinnerStatus := startSpinner(out)
 
 			project, err := context.Context().Projects().ByName(args[0])
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
 			flowNamePredicate := predicateFromArgs(args, 1)
 
 			flows, err := context.Context().Flows().ListFlows(project, azkaban.MatchesAll(flowNamePredicate))
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 2, 8, 1, ' ', 0)
-			columns := []interface{}{
-				"Flow",
-				color.WhiteString("Health"),
-				color.WhiteString("Histogram (← most recent)"),
-			}
-			headerFormat := strings.Join([]string{"%s", "%-20s", "%s\n"}, "\t")
-			rowFormat := strings.Join([]string{"%s", "%-20s", "%s\n"}, "\t")
-			fmt.Fprintf(w, headerFormat, columns...)
-
 			numberOfExecutions, _ := cmd.Flags().GetUint("count")
 			numberOfDetails, _ := cmd.Flags().GetUint("details")
 			ignoreHealthy, _ := cmd.Flags().GetBool("ignore-healthy")
@@ -57,68 +47,163 @@ func newCheckProjectCmd(context Context) *cobra.Command {
 				numberOfDetails = numberOfExecutions
 			}
 			spinnerStatus.SetTotal(len(flows))
-			for i, f := range flows {
-				spinnerStatus.SetProgress(i)
-				s.Suffix = spinnerStatus.String()
-				executions, err := context.Context().Executions().ListExecutions(project, f, azkaban.NMostRecent(int(numberOfExecutions)))
+
+			execRepo := context.Context().Executions()
+			results := make([]azkaban.Executions, len(flows))
+			err = azkaban.ForEachConcurrently(len(flows), concurrencyFromFlags(cmd), func(i int) error {
+				executions, err := execRepo.ListExecutions(project, flows[i], azkaban.NMostRecent(int(numberOfExecutions)))
+				if err != nil {
+					return err
+				}
+				results[i] = executions
+				spinnerStatus.AddProgress()
 				if executions.Health().IsHealthy() && ignoreHealthy {
 					spinnerStatus.AddIgnored()
-					continue
 				}
-				if err != nil {
-					log.Fatal(err)
+				return nil
+			})
+			spinnerStatus.Stop()
+			if err != nil {
+				fatal(err)
+			}
+
+			checked := []flowExecutions{}
+			views := []flowHealthView{}
+			for i, f := range flows {
+				executions := results[i]
+				if executions.Health().IsHealthy() && ignoreHealthy {
+					continue
 				}
+				checked = append(checked, flowExecutions{Project: project, Flow: f, Executions: executions})
+				views = append(views, newFlowHealthView(project.Name, f.FlowID, executions))
+			}
 
-				fmt.Fprintf(
-					w,
-					rowFormat,
-					f.FlowID,
-					executions.Health().Colored(),
-					executions.Histogram().Histogram,
-				)
-				if executions.Health() != azkaban.Healthy {
-					for _, line := range executions.HistogramDetails(int(numberOfDetails)) {
-						fmt.Fprintf(w, rowFormat, "", color.WhiteString(""), line)
+			err = out.write(views, checked, func(writer io.Writer) {
+				w := new(tabwriter.Writer)
+				w.Init(writer, 2, 8, 1, ' ', 0)
+				columns := []interface{}{
+					"Flow",
+					color.WhiteString("Health"),
+					color.WhiteString("Histogram (← most recent)"),
+				}
+				headerFormat := strings.Join([]string{"%s", "%-20s", "%s\n"}, "\t")
+				rowFormat := strings.Join([]string{"%s", "%-20s", "%s\n"}, "\t")
+				fmt.Fprintf(w, headerFormat, columns...)
+
+				for _, c := range checked {
+					executions := c.Executions
+					fmt.Fprintf(
+						w,
+						rowFormat,
+						c.Flow.FlowID,
+						executions.Health().Colored(),
+						executions.Histogram().Histogram,
+					)
+					if executions.Health() != azkaban.Healthy {
+						for _, line := range executions.HistogramDetails(int(numberOfDetails)) {
+							fmt.Fprintf(w, rowFormat, "", color.WhiteString(""), line)
+						}
 					}
 				}
+				w.Flush()
+			})
+			if err != nil {
+				fatal(err)
 			}
-			s.Stop()
-			w.Flush()
 		},
 	}
 
 	cmd.Flags().UintP("count", "c", 5, "how many executions to check")
 	cmd.Flags().UintP("details", "d", 3, "for how many executions to show details")
 	cmd.Flags().BoolP("ignore-healthy", "i", false, "show only non-healthy flows")
+	addConcurrencyFlag(cmd)
+	addOutputFlags(cmd)
 
 	return cmd
 }
 
+// flowExecutions are the recent executions of a flow as checked by check project, the value go-templates see
+type flowExecutions struct {
+	Project    azkaban.Project
+	Flow       azkaban.Flow
+	Executions azkaban.Executions
+}
+
+// Health returns the health of the flow based on its recent executions
+func (f flowExecutions) Health() azkaban.Health {
+	return f.Executions.Health()
+}
+
+// spinnerStatus shows the progress of fetching executions as the suffix of a spinner. It is safe for concurrent use.
 type spinnerStatus struct {
+	mutex    sync.Mutex
+	spinner  *spinner.Spinner
 	total    int
 	ignored  int
 	progress int
 }
 
-func newSpinnerStatus() *spinnerStatus {
-	return &spinnerStatus{
+// startSpinner starts a spinner showing the progress of fetching executions. The spinner writes to stdout, so it is
+// only shown along with human readable output.
+func startSpinner(out output) *spinnerStatus {
+	s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
+	status := newSpinnerStatus(s)
+	if out.isTable() {
+		s.Start()
+	}
+	return status
+}
+
+func newSpinnerStatus(s *spinner.Spinner) *spinnerStatus {
+	status := &spinnerStatus{
+		spinner:  s,
 		total:    0,
 		ignored:  0,
 		progress: 0,
 	}
+	status.update()
+	return status
 }
+
 func (s *spinnerStatus) SetTotal(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 	s.total = n
+	s.update()
 }
 
-func (s *spinnerStatus) SetProgress(n int) {
-	s.progress = n
+func (s *spinnerStatus) AddProgress() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.progress++
+	s.update()
 }
+
 func (s *spinnerStatus) AddIgnored() {
-	s.ignored += 1
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.ignored++
+	s.update()
+}
+
+func (s *spinnerStatus) Stop() {
+	s.spinner.Stop()
+}
+
+// update sets the spinner's suffix, callers must hold the mutex
+func (s *spinnerStatus) update() {
+	s.spinner.Lock()
+	s.spinner.Suffix = s.string()
+	s.spinner.Unlock()
 }
 
 func (s *spinnerStatus) String() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.string()
+}
+
+func (s *spinnerStatus) string() string {
 	if s.total == 0 {
 		return " fetching flows..."
 	}
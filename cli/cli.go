@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"fmt"
 	"github.com/ilikeorangutans/harbormaster/azkaban"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"log"
 	"net/url"
 	"os"
+	"strconv"
+	"time"
 )
 
 // NewCLI builds the full cobra root command with all sub commands attached
@@ -19,10 +22,23 @@ func NewCLI() *cobra.Command {
 
 	rootCmd := NewRootCmd()
 	rootCmd.AddCommand(NewLoginCmd(context))
+	rootCmd.AddCommand(NewProfileCmd(context))
 	rootCmd.AddCommand(NewGetCmd(context))
 	rootCmd.AddCommand(NewLogCmd(context))
 	rootCmd.AddCommand(NewCheckCmd(context))
 	rootCmd.AddCommand(NewReportCmd(context))
+	rootCmd.AddCommand(NewRunCmd(context))
+	rootCmd.AddCommand(NewRetryCmd(context))
+	rootCmd.AddCommand(NewCancelCmd(context))
+	rootCmd.AddCommand(NewScheduleCmd(context))
+	rootCmd.AddCommand(NewPauseCmd(context))
+	rootCmd.AddCommand(NewResumeCmd(context))
+	rootCmd.AddCommand(NewProjectCmd(context))
+	rootCmd.AddCommand(NewValidateCmd(context))
+	rootCmd.AddCommand(NewWatchCmd(context))
+	rootCmd.AddCommand(NewWaitCmd(context))
+	rootCmd.AddCommand(NewAnalyzeCmd(context))
+	rootCmd.AddCommand(NewGraphCmd(context))
 
 	completionCommand := &cobra.Command{
 		Use:   "completion",
@@ -63,24 +79,98 @@ type Context struct {
 	DumpResponses bool
 	client        *azkaban.Client
 	context       *azkaban.Context
+	profile       *profile
 }
 
+// ProfileName returns the name of the selected profile, either given by flag or environment, or the current profile
+// of the config file.
+func (c *Context) ProfileName() string {
+	if name := viper.GetString("profile"); name != "" {
+		return name
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	return cfg.CurrentProfile
+}
+
+// Profile returns the selected profile, or an empty profile if none is selected.
+func (c *Context) Profile() profile {
+	if c.profile != nil {
+		return *c.profile
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		fatal(err)
+	}
+	p, err := cfg.Profile(c.ProfileName())
+	if err != nil {
+		fatal(err)
+	}
+	c.profile = &p
+	return p
+}
+
+// SessionID returns the session ID given by flag or environment, or the one stored for the current host.
 func (c *Context) SessionID() string {
-	return viper.GetString("session-id")
+	if sessionID := viper.GetString("session-id"); sessionID != "" {
+		return sessionID
+	}
+
+	store, err := loadSessionStore()
+	if err != nil {
+		log.Printf("could not load stored sessions: %s", err)
+		return ""
+	}
+	session, _ := store.Get(c.Host())
+	return session.SessionID
 }
+
 func (c *Context) Project() string {
-	return viper.GetString("project")
+	if project := viper.GetString("project"); project != "" {
+		return project
+	}
+	return c.Profile().Project
 }
 
 func (c *Context) Host() string {
-	azkabanURL, err := url.Parse(viper.GetString("host"))
+	host := viper.GetString("host")
+	if host == "" {
+		host = c.Profile().Host
+	}
+	azkabanURL, err := url.Parse(host)
 	if err != nil {
-		log.Fatal(err)
+		fatal(err)
 	}
 
 	return azkabanURL.String()
 }
 
+// RateLimit returns the maximum number of requests per second given by flag or environment, or the one of the
+// profile. 0 means no limit.
+func (c *Context) RateLimit() float64 {
+	if rateLimit := viper.GetFloat64("rate-limit"); rateLimit > 0 {
+		return rateLimit
+	}
+	return c.Profile().RateLimit
+}
+
+// Timeout returns the HTTP timeout given by flag or environment, or the one of the profile. 0 means the client's
+// default.
+func (c *Context) Timeout() time.Duration {
+	if timeout := viper.GetDuration("timeout"); timeout > 0 {
+		return timeout
+	}
+	timeout, err := c.Profile().timeout()
+	if err != nil {
+		fatal(err)
+	}
+	return timeout
+}
+
 func (c *Context) Context() *azkaban.Context {
 	if c.context != nil {
 		return c.context
@@ -97,10 +187,82 @@ func (c *Context) Client() *azkaban.Client {
 	var err error
 	c.client, err = azkaban.ConnectWithSessionID(c.Host(), c.SessionID())
 	if err != nil {
-		panic(err)
+		fatal(err)
 	}
 
 	c.client.DumpResponses = c.DumpResponses
+	if timeout := c.Timeout(); timeout > 0 {
+		c.client.SetTimeout(timeout)
+	}
+	c.client.SetRateLimit(c.RateLimit())
+	c.client.RetryPolicy.MaxAttempts = viper.GetInt("retries") + 1
+	if viper.GetBool("verbose") {
+		c.client.OnRetry = func(attempt int, wait time.Duration, err error) {
+			log.Printf("attempt %d failed, retrying in %s: %s", attempt, wait.Round(time.Millisecond), err)
+		}
+	}
+	c.client.Credentials = c.credentialsProvider()
+	host := c.Host()
+	c.client.OnSessionRenewed = func(sessionID string) {
+		store, err := loadSessionStore()
+		if err == nil {
+			err = store.SaveSessionID(host, sessionID)
+		}
+		if err != nil {
+			log.Printf("could not store renewed session: %s", err)
+		}
+	}
 
 	return c.client
 }
+
+// credentialsProvider returns a provider for credentials to renew expired sessions with, according to the profile's
+// auth setting. By default that's the configured credential helper or the one remembered at login. Returns nil if
+// neither is available.
+func (c *Context) credentialsProvider() azkaban.CredentialsProvider {
+	auth := c.Profile().Auth
+	if auth == AuthSession {
+		return nil
+	}
+
+	if helper := credentialHelperCommand(c); helper != "" && auth != AuthStoredCredentials {
+		return credentialHelper(helper, c.Host())
+	}
+
+	store, err := loadSessionStore()
+	if err != nil {
+		return nil
+	}
+	if session, ok := store.Get(c.Host()); ok && session.CredentialHelper != "" {
+		return credentialHelper(session.CredentialHelper, c.Host())
+	}
+
+	return nil
+}
+
+// credentialHelperCommand returns the credential helper given by flag or environment, or the one of the profile.
+func credentialHelperCommand(c *Context) string {
+	if helper := viper.GetString("credential-helper"); helper != "" {
+		return helper
+	}
+	return c.Profile().CredentialHelper
+}
+
+// parseExecutionID accepts either a plain execution ID or an Azkaban execution URL
+// (as copied from the web ui, e.g. <host>/executor?execid=12345) and returns the execution ID.
+func parseExecutionID(arg string) (int64, error) {
+	if id, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return id, nil
+	}
+
+	u, err := url.Parse(arg)
+	if err != nil {
+		return 0, err
+	}
+	unparsedExecID := u.Query().Get("execid")
+	if unparsedExecID == "" {
+		return 0, fmt.Errorf("%q is neither an execution ID nor an execution URL", arg)
+	}
+
+	return strconv.ParseInt(unparsedExecID, 10, 64)
+}
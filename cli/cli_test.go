@@ -0,0 +1,705 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/azkabantest"
+	"github.com/ilikeorangutans/harbormaster/projectdir"
+)
+
+func TestMain(m *testing.M) {
+	for _, env := range []string{HarbormasterSessionID, HarbormasterHost, HarbormasterProject, HarbormasterProfile, HarbormasterCredentialHelper, HarbormasterRateLimit, HarbormasterTimeout, HarbormasterRetries} {
+		os.Unsetenv(env)
+	}
+	os.Exit(m.Run())
+}
+
+// newTestServer starts a fake server with project "example" containing flows "daily" with jobs extract -> transform ->
+// load, and "hourly" with a single job.
+func newTestServer() *azkabantest.Server {
+	s := azkabantest.NewServer()
+	s.AddProject("example")
+	s.AddFlow("example", "daily",
+		azkaban.FlowJob{ID: "extract", Type: "command"},
+		azkaban.FlowJob{ID: "transform", Type: "command", In: []string{"extract"}},
+		azkaban.FlowJob{ID: "load", Type: "command", In: []string{"transform"}},
+	)
+	s.AddFlow("example", "hourly", azkaban.FlowJob{ID: "ping", Type: "command"})
+	return s
+}
+
+// useTempConfigDir points the config directory to a new temporary directory and returns a function removing it.
+func useTempConfigDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "harbormaster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("HARBORMASTER_CONFIG_DIR", dir)
+	return func() {
+		os.Unsetenv("HARBORMASTER_CONFIG_DIR")
+		os.RemoveAll(dir)
+	}
+}
+
+// execute runs harbormaster with the given arguments and returns everything it printed to stdout.
+func execute(t *testing.T, args ...string) string {
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		output <- string(b)
+	}()
+
+	cmd := NewCLI()
+	cmd.SetArgs(args)
+	err = cmd.Execute()
+
+	w.Close()
+	os.Stdout = stdout
+	out := <-output
+	if err != nil {
+		t.Fatalf("harbormaster %s: %s", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+// run runs harbormaster against the given server with a valid session and project "example".
+func run(t *testing.T, s *azkabantest.Server, args ...string) string {
+	return execute(t, append([]string{"--host", s.URL, "--session-id", azkabantest.DefaultSessionID, "-p", "example"}, args...)...)
+}
+
+func assertContains(t *testing.T, output string, expected ...string) {
+	t.Helper()
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("expected output to contain %q, got:\n%s", e, output)
+		}
+	}
+}
+
+func TestLoginCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	// An existing, too permissive session store is tightened
+	path := filepath.Join(os.Getenv("HARBORMASTER_CONFIG_DIR"), "sessions.json")
+	if err := ioutil.WriteFile(path, []byte(`{"sessions": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	helper := fmt.Sprintf("echo username=%s; echo password=%s", azkabantest.DefaultUsername, azkabantest.DefaultPassword)
+	out := execute(t, "--credential-helper", helper, "login", s.URL, "--remember")
+	assertContains(t, out, "export HARBORMASTER_SESSION_ID=azkabantest-session-1", "export HARBORMASTER_HOST="+s.URL)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected session store to be only readable by the user, got %s", info.Mode())
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(b), `"password"`) {
+		t.Errorf("expected no password in session store, got:\n%s", b)
+	}
+
+	// The stored session is used without --session-id, and the stored credential helper once it expired
+	s.ExpireSessions()
+	out = execute(t, "--host", s.URL, "get", "projects")
+	assertContains(t, out, "example")
+
+	store, err := loadSessionStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, _ := store.Get(s.URL)
+	if session.SessionID != "azkabantest-session-2" {
+		t.Errorf("expected renewed session to be stored, got %q", session.SessionID)
+	}
+	if session.CredentialHelper != helper {
+		t.Errorf("expected credential helper to be stored, got %q", session.CredentialHelper)
+	}
+}
+
+func TestProfileCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	config := "current-profile: dev\nprofiles:\n  dev:\n    host: http://localhost:1\n  test:\n    host: " + s.URL + "\n    project: example\n    timeout: 5s\n"
+	if err := ioutil.WriteFile(filepath.Join(os.Getenv("HARBORMASTER_CONFIG_DIR"), "config.yaml"), []byte(config), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out := execute(t, "profile", "list")
+	assertContains(t, out, "dev", "test", s.URL)
+
+	out = execute(t, "profile", "use", "test")
+	assertContains(t, out, "using profile test")
+
+	out = execute(t, "profile", "show")
+	assertContains(t, out, "Profile:             test", "Project:             example", "Timeout:             5s")
+
+	// Host and project come from the profile now
+	out = execute(t, "--session-id", azkabantest.DefaultSessionID, "get", "flows")
+	assertContains(t, out, "daily\nhourly\n")
+}
+
+func TestGetCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	s.AddProject("other")
+	s.AddExecution("example", "daily", "SUCCEEDED", time.Now().Add(-2*time.Hour))
+	running := s.AddExecution("example", "hourly", "RUNNING", time.Now())
+
+	out := run(t, s, "get", "projects")
+	assertContains(t, out, "example", "other")
+
+	out = run(t, s, "get", "flows")
+	assertContains(t, out, "daily", "hourly")
+
+	out = run(t, s, "get", "executions", "daily")
+	assertContains(t, out, "SUCCEEDED", "2 hours ago")
+
+	out = run(t, s, "get", "running")
+	assertContains(t, out, "hourly", "RUNNING")
+	if !strings.Contains(out, fmt.Sprintf("\n%d ", running.ID)) || strings.Contains(out, "daily") {
+		t.Errorf("expected only execution %d, got:\n%s", running.ID, out)
+	}
+
+	s.AddFlow("other", "nightly", azkaban.FlowJob{ID: "backup", Type: "command"})
+	s.AddExecution("other", "nightly", "RUNNING", time.Now())
+	if out = run(t, s, "get", "running"); strings.Contains(out, "nightly") {
+		t.Errorf("expected only running executions of the current project, got:\n%s", out)
+	}
+	out = run(t, s, "get", "running", "--all-projects")
+	assertContains(t, out, "hourly", "nightly")
+}
+
+func TestRetries(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	s.FailNext(1, http.StatusServiceUnavailable)
+	out := run(t, s, "-v", "get", "projects")
+	assertContains(t, out, "example")
+}
+
+func TestLogCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "FAILED", time.Now())
+	e.Job("load").Log = "loading\nERROR: disk full\n"
+
+	out := run(t, s, "log", "load", "1")
+	if out != e.Job("load").Log {
+		t.Errorf("unexpected log %q", out)
+	}
+
+	out = run(t, s, "log", s.URL+"/executor?execid=1&job=load")
+	if out != e.Job("load").Log {
+		t.Errorf("unexpected log %q", out)
+	}
+}
+
+func TestWatchCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "RUNNING", time.Now().Add(-time.Minute))
+	e.Job("transform").Status = "READY"
+	e.Job("load").Status = "READY"
+
+	go func() {
+		for _, job := range []string{"extract", "transform", "load"} {
+			time.Sleep(20 * time.Millisecond)
+			s.UpdateJob(e.ID, job, "SUCCEEDED")
+		}
+	}()
+
+	out := run(t, s, "watch", s.URL+"/executor?execid=1", "--interval", "5ms")
+	assertContains(t, out,
+		"Execution 1 of example daily: RUNNING",
+		"extract",
+		"└─ transform",
+		"   └─ load",
+		"transform SUCCEEDED",
+		"execution 1 of example daily SUCCEEDED after",
+	)
+}
+
+func TestWaitCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "RUNNING", time.Now().Add(-time.Minute))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		for _, job := range []string{"extract", "transform", "load"} {
+			s.UpdateJob(e.ID, job, "SUCCEEDED")
+		}
+	}()
+
+	// --timeout is the HTTP timeout of the root command, not how long to wait
+	out := run(t, s, "wait", "1", "--max-wait", "10s", "--timeout", "5s", "--interval", "5ms")
+	assertContains(t, out, "execution 1 of example daily SUCCEEDED after")
+}
+
+func TestAnalyzeCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	start := time.Now().Add(-2 * time.Hour)
+	e := s.AddExecution("example", "daily", "SUCCEEDED", start)
+	for id, minutes := range map[string][2]int{"extract": {0, 10}, "transform": {10, 40}, "load": {45, 60}} {
+		e.Job(id).StartTime = start.Add(time.Duration(minutes[0]) * time.Minute)
+		e.Job(id).EndTime = start.Add(time.Duration(minutes[1]) * time.Minute)
+	}
+
+	out := run(t, s, "analyze", "1", "--width", "12")
+	assertContains(t, out,
+		"Critical path: extract -> transform -> load",
+		"transform 30m, 0s (50%)",
+		"|##          |",
+		"|  ######    |",
+		"|         ###|",
+	)
+
+	var views []map[string]interface{}
+	decodeJSON(t, run(t, s, "analyze", "1", "-o", "json"), &views)
+	if len(views) != 3 || views[2]["job"] != "load" || views[2]["waitSeconds"] != float64(300) || views[2]["critical"] != true || views[0]["slackSeconds"] != float64(0) {
+		t.Errorf("unexpected analysis %v", views)
+	}
+}
+
+func TestGraphCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	e := s.AddExecution("example", "daily", "FAILED", time.Now().Add(-time.Hour))
+	e.Job("transform").Status = "FAILED"
+	e.Job("load").Status = "CANCELLED"
+
+	assertContains(t, run(t, s, "graph", "daily"),
+		"Flow daily",
+		"extract",
+		"└─ transform",
+		"   └─ load",
+	)
+	assertContains(t, run(t, s, "graph", "daily", "-o", "dot", "--execution", "1"),
+		`digraph "daily" {`,
+		`"transform" [label="transform\ncommand\nFAILED", style=filled, fillcolor="#f4a6a6"];`,
+		`"extract" -> "transform";`,
+		`"transform" -> "load";`,
+	)
+	assertContains(t, run(t, s, "graph", "daily", "-o", "mermaid", "--execution", "1"),
+		"flowchart LR",
+		`job0["extract<br/>command<br/>SUCCEEDED"]`,
+		"job0 --> job1",
+		"classDef failed fill:#f4a6a6",
+		"class job1 failed",
+	)
+}
+
+func TestCheckCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	s.AddExecution("example", "hourly", "SUCCEEDED", time.Now().Add(-time.Hour))
+	s.AddExecution("example", "daily", "SUCCEEDED", time.Now().Add(-48*time.Hour))
+	failed := s.AddExecution("example", "daily", "FAILED", time.Now().Add(-24*time.Hour))
+	failed.Job("load").Log = "loading\nERROR: disk full\ndone\n"
+	s.AddSchedule("example", "daily", "0 0 2 ? * *")
+
+	out := run(t, s, "check", "flow", "hourly")
+	assertContains(t, out, "Checking status of example hourly", "0 failures, 1 successes", "not scheduled")
+
+	out = run(t, s, "check", "flow", "daily")
+	assertContains(t, out, "1 failures, 1 successes", "Schedule:        0 0 2 ? * *", `Execution failed in "load"`, "ERROR: disk full")
+	if strings.Contains(out, "done") {
+		t.Errorf("expected only log lines of interest, got:\n%s", out)
+	}
+
+	// Failures in embedded flows are reported as the failed job, not the embedded flow
+	nested := s.AddExecution("example", "daily", "FAILED", time.Now().Add(-time.Hour))
+	load := nested.Job("load")
+	load.Type, load.Flow = "flow", "load_flow"
+	load.Jobs = []*azkabantest.JobExecution{
+		{ID: "prepare", Type: "command", Status: "SUCCEEDED", StartTime: load.StartTime, EndTime: load.EndTime},
+		{ID: "write", Type: "command", In: []string{"prepare"}, Status: "FAILED", StartTime: load.StartTime, EndTime: load.EndTime, Log: "ERROR: table locked\n"},
+	}
+	out = run(t, s, "check", "flow", "daily")
+	assertContains(t, out, `Execution failed in "load:write"`, "ERROR: table locked")
+
+	out = run(t, s, "check", "project", "example")
+	assertContains(t, out, "daily", "hourly")
+
+	out = run(t, s, "check", "project", "example", "--ignore-healthy")
+	assertContains(t, out, "daily")
+	if strings.Contains(out, "hourly") {
+		t.Errorf("expected healthy flows to be ignored, got:\n%s", out)
+	}
+}
+
+func TestRunCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	out := run(t, s, "run", "daily", "--param", "date=2019-08-01", "--disable", "extract", "--failure-action", "finish-possible")
+	assertContains(t, out, "submitted execution 1 of example daily")
+
+	e := s.Execution(1)
+	if e == nil {
+		t.Fatal("expected execution 1")
+	}
+	expected := azkaban.ExecutionOptions{
+		FlowParameters: map[string]string{"date": "2019-08-01"},
+		DisabledJobs:   []string{"extract"},
+		FailureAction:  azkaban.FinishPossible,
+	}
+	if !reflect.DeepEqual(e.Options, expected) {
+		t.Errorf("expected options %v, got %v", expected, e.Options)
+	}
+}
+
+func TestRetryCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	s.AddExecution("example", "daily", "FAILED", time.Now())
+
+	out := run(t, s, "retry", "1", "--dry-run")
+	assertContains(t, out, "example daily", "extract, transform")
+	if s.Execution(2) != nil {
+		t.Error("expected no execution to be submitted with --dry-run")
+	}
+
+	out = run(t, s, "retry", s.URL+"/executor?execid=1")
+	assertContains(t, out, "submitted execution 2")
+	if e := s.Execution(2); e == nil || e.Job("load").Status != "READY" || e.Job("transform").Status != "DISABLED" {
+		t.Errorf("unexpected retry execution %v", e)
+	}
+}
+
+func TestExecutionActionCmds(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	daily := s.AddExecution("example", "daily", "RUNNING", time.Now())
+	hourly := s.AddExecution("example", "hourly", "RUNNING", time.Now())
+
+	out := run(t, s, "pause", "1")
+	assertContains(t, out, "paused execution 1")
+	if daily.Status != "PAUSED" {
+		t.Errorf("expected PAUSED, got %s", daily.Status)
+	}
+
+	out = run(t, s, "resume", "1")
+	assertContains(t, out, "resumed execution 1")
+	if daily.Status != "RUNNING" {
+		t.Errorf("expected RUNNING, got %s", daily.Status)
+	}
+
+	out = run(t, s, "cancel", "--flow", "hour")
+	assertContains(t, out, "cancelled execution 2")
+	if hourly.Status != "KILLED" || daily.Status != "RUNNING" {
+		t.Errorf("expected only hourly to be killed, got %s and %s", hourly.Status, daily.Status)
+	}
+}
+
+func TestProjectCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	out := run(t, s, "project", "create", "new", "-d", "new project")
+	assertContains(t, out, "created project new")
+
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "README"), []byte("no flows yet"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out = run(t, s, "-p", "new", "project", "upload", dir)
+	assertContains(t, out, "to project new, version 1", "No flows found in project.")
+	if p := s.Project("new"); p.Description != "new project" || len(p.Versions) != 1 {
+		t.Errorf("unexpected project %v", p)
+	}
+
+	out = run(t, s, "-p", "new", "project", "versions")
+	assertContains(t, out, "Version", "azkaban", "Uploaded project files zip new.zip")
+
+	file := filepath.Join(dir, "new.zip")
+	out = run(t, s, "-p", "new", "project", "download", "-f", file)
+	assertContains(t, out, "downloaded the latest version of project new to "+file)
+	if content, err := ioutil.ReadFile(file); err != nil || !bytes.Equal(content, s.Project("new").Versions[0].Zip) {
+		t.Errorf("expected downloaded zip to match the upload, got %v", err)
+	}
+
+	out = run(t, s, "project", "delete", "new", "--yes")
+	assertContains(t, out, "deleted project new")
+	if s.Project("new") != nil {
+		t.Error("expected project to be deleted")
+	}
+}
+
+func TestProjectDiffCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	s.Project("example").Flows[0].Properties["transform"] = map[string]string{"command": "transform.sh"}
+
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		".project":    "azkaban-flow-version: 2.0\n",
+		"daily.flow":  "nodes:\n  - name: extract\n    type: command\n  - name: transform\n    type: command\n    dependsOn: [extract]\n    config:\n      command: transform.sh --full\n  - name: load\n    type: command\n    dependsOn: [transform]\n",
+		"weekly.flow": "nodes:\n  - name: report\n    type: command\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := run(t, s, "project", "diff", dir)
+	assertContains(t, out, "~ daily/transform command: transform.sh -> transform.sh --full", "- flow hourly", "+ flow weekly")
+
+	var changes []map[string]interface{}
+	decodeJSON(t, run(t, s, "project", "diff", dir, "-o", "json"), &changes)
+	if len(changes) != 3 || changes[0]["change"] != "changed" || changes[0]["property"] != "command" {
+		t.Errorf("unexpected changes %v", changes)
+	}
+}
+
+func TestValidateCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	dir, err := ioutil.TempDir("", "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"extract.job": "type=command\ncommand=extract ${date}\n",
+		"load.job":    "type=slack\ndependencies=extract\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out := execute(t, "validate", dir, "--job-type", "slack")
+	assertContains(t, out, "extract.job: warning: job extract uses ${date}", "Flow 1.0 project with 1 flows: 0 errors, 1 warnings")
+
+	var problems []map[string]interface{}
+	decodeJSON(t, execute(t, "validate", dir, "--job-type", "slack", "-o", "json"), &problems)
+	if len(problems) != 1 || problems[0]["severity"] != "warning" || problems[0]["job"] != "extract" {
+		t.Errorf("unexpected problems %v", problems)
+	}
+
+	out = run(t, s, "project", "upload", dir, "--validate", "--job-type", "slack")
+	assertContains(t, out, "1 warnings", "to project example, version 1")
+
+	// Zips are extracted and validated the same way
+	zip := filepath.Join(os.Getenv("HARBORMASTER_CONFIG_DIR"), "flows.zip")
+	var buf bytes.Buffer
+	if err := projectdir.Zip(dir, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(zip, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out = run(t, s, "project", "upload", zip, "--validate", "--job-type", "slack")
+	assertContains(t, out, "extract.job: warning: job extract uses ${date}", "to project example, version 2")
+}
+
+func TestScheduleCmd(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+
+	out := run(t, s, "schedule", "set", "daily", "--cron", "0 0 2 ? * MON-FRI", "--display-timezone", "UTC", "--param", "env=prod")
+	assertContains(t, out, "scheduled example daily (schedule 1)", "Next execution:", "07:00:00 UTC")
+	schedules := s.Schedules()
+	if len(schedules) != 1 || schedules[0].Options.FlowParameters["env"] != "prod" {
+		t.Fatalf("unexpected schedules %v", schedules)
+	}
+
+	out = run(t, s, "schedule", "list")
+	assertContains(t, out, "example", "daily", "0 0 2 ? * MON-FRI")
+
+	out = run(t, s, "schedule", "show", "daily", "-n", "2", "--timezone", "UTC")
+	assertContains(t, out, "Schedule ID:     1", "Upcoming:")
+
+	out = run(t, s, "schedule", "remove", "daily")
+	assertContains(t, out, "removed schedule 1 of example daily")
+
+	out = run(t, s, "schedule", "show", "daily")
+	assertContains(t, out, "example daily is not scheduled")
+}
+
+func TestCompletionCmd(t *testing.T) {
+	out := execute(t, "completion", "bash")
+	assertContains(t, out, "harbormaster")
+}
+
+func TestMachineReadableOutput(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	start := time.Date(2019, time.August, 1, 10, 0, 0, 0, time.UTC)
+	s.AddExecution("example", "daily", "SUCCEEDED", start)
+	s.AddExecution("example", "daily", "FAILED", start.Add(24*time.Hour))
+	s.AddExecution("example", "hourly", "RUNNING", time.Now())
+	s.AddSchedule("example", "daily", "0 0 2 ? * *")
+
+	out := run(t, s, "get", "projects", "-o", "csv")
+	if out != "id,name\n1,example\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out = run(t, s, "get", "flows", "d", "-o", "template", "--template", "{{.Project}}/{{.FlowID}}")
+	if out != "example/daily\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	var executions []executionView
+	decodeJSON(t, run(t, s, "get", "executions", "daily", "-o", "json"), &executions)
+	if len(executions) != 2 || executions[0].Status != "FAILED" || executions[1].DurationSeconds != 3600 || !executions[1].StartTime.Equal(start) {
+		t.Errorf("unexpected executions %v", executions)
+	}
+
+	var running []executionView
+	decodeJSON(t, run(t, s, "get", "running", "-o", "json"), &running)
+	if len(running) != 1 || running[0].FlowID != "hourly" || running[0].EndTime != nil {
+		t.Errorf("unexpected running executions %v", running)
+	}
+
+	out = run(t, s, "check", "project", "example", "-o", "yaml")
+	assertContains(t, out, "- project: example\n  flowId: daily\n  health: critical\n  failures: 1\n")
+
+	var status flowStatusView
+	decodeJSON(t, run(t, s, "check", "flow", "daily", "-o", "json"), &status)
+	if status.Health != "critical" || status.FailedJob != "load" || status.Schedule == nil || status.Schedule.CronExpression != "0 0 2 ? * *" || len(status.Schedule.Upcoming) != 5 {
+		t.Errorf("unexpected status %v", status)
+	}
+
+	out = run(t, s, "report", "aet", "-o", "csv")
+	if out != "flowId,successCount,failureCount,averageSeconds\ndaily,1,1,3600\nhourly,0,1,0\n" {
+		t.Errorf("unexpected report %q", out)
+	}
+	out = run(t, s, "report", "aet", "--format", "tsv")
+	assertContains(t, out, "daily\t1\t1\t3600\n")
+	out = run(t, s, "report", "aet")
+	assertContains(t, out, "Average Time", "1h")
+}
+
+func decodeJSON(t *testing.T, s string, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(s), v); err != nil {
+		t.Fatalf("%s: %s", err, s)
+	}
+}
+
+func TestGoTemplateOutput(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	start := time.Now().Add(-3 * time.Hour)
+	s.AddExecution("example", "daily", "SUCCEEDED", start.Add(-24*time.Hour))
+	s.AddExecution("example", "daily", "FAILED", start)
+
+	out := run(t, s, "get", "executions", "daily", "-o", `go-template={{range .}}{{.ID}} {{color "red" .Status}} {{duration .Duration}} {{humanizeTime .StartTime}}{{"\n"}}{{end}}`)
+	if out != "2 FAILED 1h, 0s 3 hours ago\n1 SUCCEEDED 1h, 0s 1 day ago\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	// The same functions are available per row with -o template
+	out = run(t, s, "get", "executions", "daily", "-o", "template", "--template", `{{color "red" .Status}}`)
+	if out != "FAILED\nSUCCEEDED\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out = run(t, s, "get", "projects", "-o", `go-template={{range .}}{{.Name}}{{end}}`)
+	if out != "example" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	file := filepath.Join(os.Getenv("HARBORMASTER_CONFIG_DIR"), "status.tmpl")
+	text := `{{.Flow.FlowID}} is {{.Health}}{{with .FailedJob}}, {{.ID}} failed in {{$.LastExecution.ExecutionID}}{{end}}`
+	if err := ioutil.WriteFile(file, []byte(text), 0600); err != nil {
+		t.Fatal(err)
+	}
+	out = run(t, s, "check", "flow", "daily", "-o", "go-template-file="+file)
+	if out != "daily is critical, load failed in 2" {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out = run(t, s, "check", "project", "example", "-o", `go-template={{range .}}{{.Flow.FlowID}}={{.Health}} {{end}}`)
+	if out != "daily=critical hourly=healthy " {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	out = run(t, s, "report", "aet", "-o", `go-template={{range .}}{{.FlowID}} {{duration .AverageTime}};{{end}}`)
+	if out != "daily 1h, 0s;hourly ;" {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestConcurrentChecksKeepOrder(t *testing.T) {
+	defer useTempConfigDir(t)()
+	s := newTestServer()
+	defer s.Close()
+	var expected []string
+	for i := 0; i < 20; i++ {
+		flow := fmt.Sprintf("flow%02d", i)
+		s.AddFlow("example", flow, azkaban.FlowJob{ID: "job", Type: "command"})
+		s.AddExecution("example", flow, "SUCCEEDED", time.Now())
+		expected = append(expected, flow)
+	}
+
+	out := run(t, s, "--rate-limit", "1000", "check", "project", "example", "/^flow/", "--concurrency", "5", "-o", "go-template={{range .}}{{.Flow.FlowID}} {{end}}")
+	if out != strings.Join(expected, " ")+" " {
+		t.Errorf("unexpected order %q", out)
+	}
+
+	out = run(t, s, "report", "aet", "/^flow/", "--concurrency", "5", "-o", "go-template={{range .}}{{.FlowID}} {{end}}")
+	if out != strings.Join(expected, " ")+" " {
+		t.Errorf("unexpected order %q", out)
+	}
+}
@@ -0,0 +1,20 @@
+package cli
+
+import (
+	"github.com/spf13/cobra"
+)
+
+const defaultConcurrency = 4
+
+// addConcurrencyFlag registers --concurrency, the number of requests a command sends to Azkaban in parallel
+func addConcurrencyFlag(cmd *cobra.Command) {
+	cmd.Flags().Int("concurrency", defaultConcurrency, "how many requests to send to Azkaban in parallel")
+}
+
+func concurrencyFromFlags(cmd *cobra.Command) int {
+	concurrency, _ := cmd.Flags().GetInt("concurrency")
+	if concurrency < 1 {
+		return 1
+	}
+	return concurrency
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// AuthSession only uses the session from flags, environment, or session store
+	AuthSession = "session"
+	// AuthStoredCredentials renews expired sessions with the credential helper stored by login --remember
+	AuthStoredCredentials = "stored-credentials"
+	// AuthCredentialHelper renews expired sessions with credentials from the profile's credential helper
+	AuthCredentialHelper = "credential-helper"
+)
+
+// profile holds the settings for one Azkaban environment
+type profile struct {
+	Host    string `yaml:"host"`
+	Project string `yaml:"project,omitempty"`
+	// Auth is one of session, stored-credentials, or credential-helper. If empty, a credential helper is used if
+	// configured, stored credentials otherwise.
+	Auth             string `yaml:"auth,omitempty"`
+	CredentialHelper string `yaml:"credential-helper,omitempty"`
+	// Timeout is the HTTP timeout for requests to Azkaban, e.g. 30s or 2m
+	Timeout string `yaml:"timeout,omitempty"`
+	// RateLimit is the maximum number of requests per second sent to Azkaban, 0 for no limit
+	RateLimit float64 `yaml:"rate-limit,omitempty"`
+}
+
+func (p profile) timeout() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.Timeout)
+}
+
+func (p profile) validate() error {
+	switch p.Auth {
+	case "", AuthSession, AuthStoredCredentials:
+	case AuthCredentialHelper:
+		if p.CredentialHelper == "" {
+			return fmt.Errorf("auth %s requires credential-helper", AuthCredentialHelper)
+		}
+	default:
+		return fmt.Errorf("unknown auth %q, valid are %s, %s, %s", p.Auth, AuthSession, AuthStoredCredentials, AuthCredentialHelper)
+	}
+	if _, err := p.timeout(); err != nil {
+		return fmt.Errorf("invalid timeout %q: %s", p.Timeout, err)
+	}
+	if p.RateLimit < 0 {
+		return fmt.Errorf("invalid rate-limit %v, must not be negative", p.RateLimit)
+	}
+	return nil
+}
+
+// config is harbormaster's config file, usually ~/.config/harbormaster/config.yaml:
+//
+//	current-profile: prod
+//	profiles:
+//	  prod:
+//	    host: https://azkaban.example.com
+//	    project: warehouse
+//	    auth: credential-helper
+//	    credential-helper: echo username=$USER; echo password=$(pass azkaban/prod)
+//	    timeout: 1m
+//	  staging:
+//	    host: https://azkaban-staging.example.com
+type config struct {
+	path           string
+	CurrentProfile string             `yaml:"current-profile,omitempty"`
+	Profiles       map[string]profile `yaml:"profiles"`
+}
+
+func loadConfig() (*config, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &config{
+		path:     filepath.Join(dir, "config.yaml"),
+		Profiles: make(map[string]profile),
+	}
+
+	b, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("could not read config %s: %s", c.path, err)
+	}
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]profile)
+	}
+
+	for name, p := range c.Profiles {
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("profile %s in %s: %s", name, c.path, err)
+		}
+	}
+
+	return c, nil
+}
+
+// Profile returns the profile with the given name, or the current profile if name is empty. Returns an empty profile
+// if no name is given and there's no current profile.
+func (c *config) Profile(name string) (profile, error) {
+	if name == "" {
+		name = c.CurrentProfile
+	}
+	if name == "" {
+		return profile{}, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return p, fmt.Errorf("no profile %q in %s", name, c.path)
+	}
+	return p, nil
+}
+
+// ProfileNames returns all profile names in alphabetical order
+func (c *config) ProfileNames() []string {
+	var names []string
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *config) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(c.path, b, 0600)
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+// executionAction describes a command that applies an action to one or more executions
+type executionAction struct {
+	use     string
+	short   string
+	long    string
+	verb    string
+	perform func(*azkaban.Client, int64) error
+}
+
+// newExecutionActionCmd builds a command that applies the given action to all executions given as arguments, either
+// as execution IDs or execution URLs, and optionally to all running executions of flows matching --flow.
+func newExecutionActionCmd(context Context, action executionAction) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   action.use,
+		Short: action.short,
+		Long:  action.long,
+		Run: func(cmd *cobra.Command, args []string) {
+			flowFilter, _ := cmd.Flags().GetString("flow")
+			if len(args) == 0 && flowFilter == "" {
+				usageError("no executions given, pass execution IDs, URLs, or --flow")
+			}
+
+			var executionIDs []int64
+			for _, arg := range args {
+				execID, err := parseExecutionID(arg)
+				if err != nil {
+					fatal(err)
+				}
+				executionIDs = append(executionIDs, execID)
+			}
+
+			if flowFilter != "" {
+				running, err := runningExecutionsOfFlows(context, flowFilter)
+				if err != nil {
+					fatal(err)
+				}
+				if len(running) == 0 {
+					fmt.Printf("no running executions of flows matching %q\n", flowFilter)
+				}
+				executionIDs = append(executionIDs, running...)
+			}
+
+			if err := applyExecutionAction(context.Client(), action, executionIDs); err != nil {
+				fatal(err)
+			}
+		},
+	}
+
+	cmd.Flags().String("flow", "", "apply to all running executions of flows matching this prefix or /regex/")
+
+	return cmd
+}
+
+// applyExecutionAction applies the action to all given executions, even if it fails for some, and returns the first
+// error so the exit code tells why e.g. an execution couldn't be cancelled.
+func applyExecutionAction(client *azkaban.Client, action executionAction, executionIDs []int64) error {
+	var first error
+	failed := 0
+	for _, execID := range executionIDs {
+		if err := action.perform(client, execID); err != nil {
+			fmt.Println(err)
+			failed++
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		fmt.Printf("%s execution %d\n", action.verb, execID)
+	}
+
+	if failed > 0 {
+		log.Printf("%d of %d executions failed", failed, len(executionIDs))
+	}
+	return first
+}
+
+// runningExecutionsOfFlows returns the IDs of all running executions of flows in the current project that match the
+// given flow name filter.
+func runningExecutionsOfFlows(context Context, flowFilter string) ([]int64, error) {
+	project := azkaban.Project{Name: context.Project()}
+	flows, err := context.Context().Flows().ListFlows(project, azkaban.MatchesFlowName(flowFilter))
+	if err != nil {
+		return nil, err
+	}
+
+	var executionIDs []int64
+	for _, f := range flows {
+		running, err := context.Client().RunningExecutions(project.Name, f.FlowID)
+		if err != nil {
+			return nil, err
+		}
+		executionIDs = append(executionIDs, running...)
+	}
+
+	return executionIDs, nil
+}
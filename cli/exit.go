@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"log"
+	"net/url"
+	"os"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+// Exit codes of harbormaster, documented in the README. Scripts depend on them, don't change them.
+const (
+	exitError = 1
+	// exitUsage reports invalid or missing arguments and flags
+	exitUsage            = 2
+	exitNotFound         = 3
+	exitPermissionDenied = 4
+	exitSessionExpired   = 5
+	exitUnavailable      = 6
+	// exitFlowFailed and exitFlowKilled report the outcome of executions harbormaster waited for
+	exitFlowFailed  = 7
+	exitFlowKilled  = 8
+	exitWaitTimeout = 9
+	// exitInterrupted is what shells report for commands stopped with Ctrl-C
+	exitInterrupted = 130
+)
+
+// exitCode returns the exit code for the given error, so scripts can tell e.g. a missing project from Azkaban being
+// unreachable.
+func exitCode(err error) int {
+	switch {
+	case err == azkaban.ErrSessionExpired:
+		return exitSessionExpired
+	case azkaban.IsNotFound(err):
+		return exitNotFound
+	case azkaban.IsPermissionDenied(err):
+		return exitPermissionDenied
+	}
+
+	switch e := err.(type) {
+	case *azkaban.APIError:
+		if e.StatusCode >= 500 {
+			return exitUnavailable
+		}
+	case *url.Error:
+		return exitUnavailable
+	}
+	return exitError
+}
+
+// statusExitCode returns the exit code for a finished execution with the given status.
+func statusExitCode(status azkaban.Status) int {
+	switch status {
+	case "SUCCEEDED":
+		return 0
+	case "FAILED":
+		return exitFlowFailed
+	case "KILLED", "CANCELLED":
+		return exitFlowKilled
+	}
+	return exitError
+}
+
+// fatal logs the error and exits with its exit code.
+func fatal(err error) {
+	log.Print(err)
+	os.Exit(exitCode(err))
+}
+
+// usageError logs the formatted message and exits with exitUsage. Use it for invalid or missing arguments and flags,
+// and fatal for everything that went wrong talking to Azkaban.
+func usageError(format string, args ...interface{}) {
+	log.Printf(format, args...)
+	os.Exit(exitUsage)
+}
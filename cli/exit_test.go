@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{errors.New("something"), exitError},
+		{azkaban.ErrSessionExpired, exitSessionExpired},
+		{&azkaban.APIError{Message: "Project foo doesn't exist.", Err: azkaban.ErrNotFound}, exitNotFound},
+		{&azkaban.APIError{Message: "Permission denied", Err: azkaban.ErrPermissionDenied}, exitPermissionDenied},
+		{&azkaban.APIError{Message: "Execution 1 is not running."}, exitError},
+		{&azkaban.APIError{StatusCode: 502}, exitUnavailable},
+		{&url.Error{Op: "Get", URL: "http://localhost:1", Err: errors.New("connection refused")}, exitUnavailable},
+	}
+
+	for _, test := range tests {
+		if code := exitCode(test.err); code != test.expected {
+			t.Errorf("%v: expected exit code %d, got %d", test.err, test.expected, code)
+		}
+	}
+}
+
+func TestExitCodeMissingProject(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	_, err := azkaban.NewProjectRepository(s.Client()).ByName("missing")
+	if code := exitCode(err); code != exitNotFound {
+		t.Errorf("%v: expected exit code %d, got %d", err, exitNotFound, code)
+	}
+}
+
+func TestExitCodeExecutionAction(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+	running := s.AddExecution("example", "daily", "RUNNING", time.Now())
+
+	cancel := executionAction{verb: "cancelled", perform: (*azkaban.Client).CancelExecution}
+	err := applyExecutionAction(s.Client(), cancel, []int64{running.ID, 999})
+	if code := exitCode(err); code != exitNotFound {
+		t.Errorf("%v: expected exit code %d, got %d", err, exitNotFound, code)
+	}
+}
+
+func TestStatusExitCode(t *testing.T) {
+	expected := map[azkaban.Status]int{
+		"SUCCEEDED": 0,
+		"FAILED":    exitFlowFailed,
+		"KILLED":    exitFlowKilled,
+		"CANCELLED": exitFlowKilled,
+		"SKIPPED":   exitError,
+	}
+	for status, code := range expected {
+		if c := statusExitCode(status); c != code {
+			t.Errorf("%s: expected exit code %d, got %d", status, code, c)
+		}
+	}
+}
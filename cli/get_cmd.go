@@ -7,8 +7,7 @@ import (
 	"github.com/ilikeorangutans/harbormaster/azkaban"
 	"github.com/ilikeorangutans/harbormaster/format"
 	"github.com/spf13/cobra"
-	"log"
-	"os"
+	"io"
 	"text/tabwriter"
 	"time"
 )
@@ -29,33 +28,51 @@ func NewGetCmd(context Context) *cobra.Command {
 }
 
 func newGetProjectsCmd(context Context) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "projects",
 		Aliases: []string{"p"},
 		Short:   "get a list of projects",
 		Run: func(cmd *cobra.Command, args []string) {
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
 			projectRepo := context.Context().Projects()
 			projects, err := projectRepo.ListProjects()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 4, 4, 1, '\t', 0)
-			fmt.Fprintln(w, "ID \t Name")
-
+			views := []projectView{}
 			for _, project := range projects {
-				fmt.Fprintf(
-					w,
-					"%d \t %s \n",
-					project.ID,
-					project.Name,
-				)
+				views = append(views, newProjectView(project))
 			}
-			w.Flush()
 
+			err = out.write(views, projects, func(writer io.Writer) {
+				w := new(tabwriter.Writer)
+				w.Init(writer, 4, 4, 1, '\t', 0)
+				fmt.Fprintln(w, "ID \t Name")
+
+				for _, project := range projects {
+					fmt.Fprintf(
+						w,
+						"%d \t %s \n",
+						project.ID,
+						project.Name,
+					)
+				}
+				w.Flush()
+			})
+			if err != nil {
+				fatal(err)
+			}
 		},
 	}
+
+	addOutputFlags(cmd)
+
+	return cmd
 }
 
 func predicateFromArgs(args []string, position int) func(azkaban.Flow) bool {
@@ -67,104 +84,171 @@ func predicateFromArgs(args []string, position int) func(azkaban.Flow) bool {
 }
 
 func newGetFlowsCmd(context Context) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "flows",
 		Aliases: []string{"f"},
 		Short:   "Lists flows and optionally filters them either by prefix or regex",
 		Args:    cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			client := context.Client()
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
 
-			context.Context().Flows().ListFlows(azkaban.Project{Name: context.Project()}, azkaban.MatchesAll(predicateFromArgs(args, 0)))
-			flows, err := client.ListFlows(context.Project())
+			flows, err := context.Context().Flows().ListFlows(azkaban.Project{Name: context.Project()}, azkaban.MatchesAll(predicateFromArgs(args, 0)))
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
+			views := []flowView{}
 			for _, f := range flows {
-				fmt.Printf("%s\n", f.FlowID)
+				views = append(views, flowView{Project: context.Project(), FlowID: f.FlowID})
+			}
+
+			err = out.write(views, flows, func(w io.Writer) {
+				for _, f := range flows {
+					fmt.Fprintf(w, "%s\n", f.FlowID)
+				}
+			})
+			if err != nil {
+				fatal(err)
 			}
 		},
 	}
+
+	addOutputFlags(cmd)
+
+	return cmd
 }
 
 func newGetExecutionsCmd(context Context) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "executions",
 		Aliases: []string{"e"},
 		Short:   "Get executions for a flow",
-		Args:    cobra.MaximumNArgs(1),
+		Args:    cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
 			client := context.Client()
 			executions, err := client.FlowExecutions(context.Project(), args[0], azkaban.TenMostRecent)
 			if err != nil {
-				panic(err)
-			}
-
-			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 4, 4, 2, ' ', 0)
-			fmt.Fprintf(
-				w,
-				"%s\t%s\t%s\t%s\t%s\n",
-				"ExecID",
-				color.WhiteString("Status"),
-				"Runtime",
-				"When",
-				"Start Time",
-			)
-
-			for _, e := range executions {
+				fatal(err)
+			}
+
+			err = out.write(newExecutionViews(context.Project(), args[0], executions), executions, func(writer io.Writer) {
+				w := new(tabwriter.Writer)
+				w.Init(writer, 4, 4, 2, ' ', 0)
 				fmt.Fprintf(
 					w,
-					"%d \t%s \t%s \t%s \t%s\n",
-					e.ID,
-					e.Status.Colored(),
-					format.DurationHumanReadable(e.Duration()),
-					humanize.Time(e.StartTime.Time()),
-					e.StartTime.Time().Format(time.RFC1123),
+					"%s\t%s\t%s\t%s\t%s\n",
+					"ExecID",
+					color.WhiteString("Status"),
+					"Runtime",
+					"When",
+					"Start Time",
 				)
+
+				for _, e := range executions {
+					fmt.Fprintf(
+						w,
+						"%d \t%s \t%s \t%s \t%s\n",
+						e.ID,
+						e.Status.Colored(),
+						format.DurationHumanReadable(e.Duration()),
+						humanize.Time(e.StartTime.Time()),
+						e.StartTime.Time().Format(time.RFC1123),
+					)
+				}
+				w.Flush()
+			})
+			if err != nil {
+				fatal(err)
 			}
-			w.Flush()
 		},
 	}
+
+	addOutputFlags(cmd)
+
+	return cmd
 }
 
 func newGetRunningCmd(context Context) *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:     "running",
 		Aliases: []string{"r"},
-		Short:   "Get currently running flows",
+		Short:   "Get currently running flows of the current project",
+		Long: `Lists the running executions of the current project, given with --project or
+the default project of the profile. With --all-projects it lists the running
+executions of all projects you may read instead. Azkaban can only list running
+executions per flow, so that takes a few requests for every flow on the server:
+
+# harbormaster get running --all-projects`,
 		Run: func(cmd *cobra.Command, args []string) {
-			executions, err := context.Client().Running()
+			out, err := outputFromFlags(cmd)
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 4, 4, 2, ' ', 0)
-			fmt.Fprintf(
-				w,
-				"%s\t%s\t%s\t%s\t%s\n",
-				"Project",
-				"FlowID",
-				color.WhiteString("Status"),
-				"Start time",
-				"Runtime",
-			)
+			var executions []azkaban.FlowExecution
+			allProjects, _ := cmd.Flags().GetBool("all-projects")
+			switch {
+			case allProjects:
+				executions, err = context.Client().Running()
+			case context.Project() != "":
+				executions, err = context.Client().RunningInProject(context.Project())
+			default:
+				usageError("no project given, pass --project, or --all-projects to list the running executions of all projects")
+			}
+			if err != nil {
+				fatal(err)
+			}
 
+			views := []executionView{}
 			for _, execution := range executions {
+				views = append(views, newExecutionView(execution.Project, execution.FlowID, execution.Execution))
+			}
+
+			err = out.write(views, executions, func(writer io.Writer) {
+				w := new(tabwriter.Writer)
+				w.Init(writer, 4, 4, 2, ' ', 0)
 				fmt.Fprintf(
 					w,
-					"%s\t%s\t%s\t%s\t%s\n",
-					execution.Project,
-					execution.FlowID,
-					execution.Status.Colored(),
-					humanize.Time(execution.StartTime.Time()),
-					format.DurationHumanReadable(time.Since(execution.StartTime.Time())),
+					"%s\t%s\t%s\t%s\t%s\t%s\n",
+					"ExecID",
+					"Project",
+					"FlowID",
+					color.WhiteString("Status"),
+					"Start time",
+					"Runtime",
 				)
-			}
 
-			w.Flush()
+				for _, execution := range executions {
+					fmt.Fprintf(
+						w,
+						"%d\t%s\t%s\t%s\t%s\t%s\n",
+						execution.ID,
+						execution.Project,
+						execution.FlowID,
+						execution.Status.Colored(),
+						humanize.Time(execution.StartTime.Time()),
+						format.DurationHumanReadable(time.Since(execution.StartTime.Time())),
+					)
+				}
+
+				w.Flush()
+			})
+			if err != nil {
+				fatal(err)
+			}
 		},
 	}
+
+	addOutputFlags(cmd)
+	cmd.Flags().Bool("all-projects", false, "list the running executions of all projects, not only of the current one")
+
+	return cmd
 }
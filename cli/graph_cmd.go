@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/spf13/cobra"
+)
+
+const (
+	graphDot     = "dot"
+	graphMermaid = "mermaid"
+	graphASCII   = "ascii"
+)
+
+func NewGraphCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph <flow>",
+		Short: "draw the jobs of a flow and their dependencies",
+		Long: `Draws the jobs of a deployed flow and their dependencies as Graphviz DOT,
+Mermaid, or as text. With --execution the jobs are coloured by their status in
+that execution of the flow:
+
+# harbormaster -p <project> graph daily -o dot | dot -Tsvg > daily.svg
+# harbormaster -p <project> graph daily -o mermaid --execution 12345`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			project := context.Project()
+			if project == "" {
+				usageError("no project given, pass --project or use a profile with a project")
+			}
+			flow := args[0]
+			format, _ := cmd.Flags().GetString("output")
+			render, ok := map[string]func(io.Writer, *azkaban.FlowGraph, map[string]azkaban.Status){
+				graphDot:     printDot,
+				graphMermaid: printMermaid,
+				graphASCII:   printASCIIGraph,
+			}[format]
+			if !ok {
+				usageError("unknown output format %q, valid are [%s, %s, %s]", format, graphASCII, graphDot, graphMermaid)
+			}
+
+			client := context.Client()
+			jobs, err := client.FlowJobList(project, flow)
+			if err != nil {
+				fatal(err)
+			}
+
+			var statuses map[string]azkaban.Status
+			if execution, _ := cmd.Flags().GetString("execution"); execution != "" {
+				executionID, err := parseExecutionID(execution)
+				if err != nil {
+					fatal(err)
+				}
+				status, err := client.FlowExecutionStatus(executionID)
+				if err != nil {
+					fatal(err)
+				}
+				if status.Project != project || status.FlowID != flow {
+					usageError("execution %d is of %s %s, not of %s %s", executionID, status.Project, status.FlowID, project, flow)
+				}
+				statuses = make(map[string]azkaban.Status)
+				for _, n := range status.Nodes {
+					statuses[n.ID] = n.Status
+				}
+			}
+
+			render(os.Stdout, jobs.Graph(), statuses)
+		},
+	}
+
+	cmd.Flags().StringP("output", "o", graphASCII, fmt.Sprintf("output format, valid are [%s, %s, %s]", graphASCII, graphDot, graphMermaid))
+	cmd.Flags().String("execution", "", "execid or execution url of an execution of the flow to colour the jobs by their status")
+
+	return cmd
+}
+
+// statusColor is the colour jobs with the given status are filled with, or "" to leave them blank.
+func statusColor(status azkaban.Status) string {
+	switch status {
+	case "SUCCEEDED":
+		return "#b7e1a1"
+	case "FAILED", "FAILED_SUCCEEDED":
+		return "#f4a6a6"
+	case "RUNNING":
+		return "#a6d8f4"
+	case "KILLED", "CANCELLED":
+		return "#e1b7e1"
+	case "PREPARING", "QUEUED", "PAUSED":
+		return "#f4e3a6"
+	case "SKIPPED", "DISABLED":
+		return "#dddddd"
+	}
+	return ""
+}
+
+// printDot prints the graph in Graphviz' DOT language.
+func printDot(w io.Writer, g *azkaban.FlowGraph, statuses map[string]azkaban.Status) {
+	fmt.Fprintf(w, "digraph %s {\n", dotQuote(g.FlowID))
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [shape=box];")
+	for _, job := range g.Jobs {
+		label := job.ID + "\\n" + job.Type
+		fill := ""
+		if status, ok := statuses[job.ID]; ok {
+			label += "\\n" + string(status)
+			if color := statusColor(status); color != "" {
+				fill = fmt.Sprintf(", style=filled, fillcolor=%s", dotQuote(color))
+			}
+		}
+		fmt.Fprintf(w, "  %s [label=%s%s];\n", dotQuote(job.ID), dotQuote(label), fill)
+	}
+	for _, job := range g.Jobs {
+		for _, next := range job.Next {
+			fmt.Fprintf(w, "  %s -> %s;\n", dotQuote(job.ID), dotQuote(next.ID))
+		}
+	}
+	fmt.Fprintln(w, "}")
+}
+
+// dotQuote quotes s as a DOT ID. Backslashes are left alone so labels can contain escapes like \n.
+func dotQuote(s string) string {
+	return `"` + strings.Replace(s, `"`, `\"`, -1) + `"`
+}
+
+// printMermaid prints the graph as a Mermaid flowchart. Jobs get generated node IDs since job IDs may contain
+// characters Mermaid doesn't allow in IDs.
+func printMermaid(w io.Writer, g *azkaban.FlowGraph, statuses map[string]azkaban.Status) {
+	ids := make(map[string]string)
+	for i, job := range g.Jobs {
+		ids[job.ID] = fmt.Sprintf("job%d", i)
+	}
+
+	fmt.Fprintln(w, "flowchart LR")
+	for _, job := range g.Jobs {
+		label := job.ID + "<br/>" + job.Type
+		if status, ok := statuses[job.ID]; ok {
+			label += "<br/>" + string(status)
+		}
+		fmt.Fprintf(w, "  %s[\"%s\"]\n", ids[job.ID], strings.Replace(label, `"`, "#quot;", -1))
+	}
+	for _, job := range g.Jobs {
+		for _, next := range job.Next {
+			fmt.Fprintf(w, "  %s --> %s\n", ids[job.ID], ids[next.ID])
+		}
+	}
+
+	classes := make(map[azkaban.Status]bool)
+	for _, job := range g.Jobs {
+		status, ok := statuses[job.ID]
+		if !ok || statusColor(status) == "" {
+			continue
+		}
+		class := strings.ToLower(string(status))
+		if !classes[status] {
+			classes[status] = true
+			fmt.Fprintf(w, "  classDef %s fill:%s\n", class, statusColor(status))
+		}
+		fmt.Fprintf(w, "  class %s %s\n", ids[job.ID], class)
+	}
+}
+
+// printASCIIGraph prints the jobs each indented below the jobs it depends on, listing the dependencies of jobs that
+// depend on more than one.
+func printASCIIGraph(writer io.Writer, g *azkaban.FlowGraph, statuses map[string]azkaban.Status) {
+	fmt.Fprintf(writer, "Flow %s\n", g.FlowID)
+
+	w := new(tabwriter.Writer)
+	w.Init(writer, 4, 4, 2, ' ', 0)
+	levels := g.Levels()
+	for _, job := range g.Jobs {
+		prefix := ""
+		if level := levels[job.ID]; level > 0 {
+			prefix = strings.Repeat("   ", level-1) + "└─ "
+		}
+		columns := []string{prefix + job.ID, job.Type}
+		if statuses != nil {
+			columns = append(columns, statuses[job.ID].Colored())
+		}
+		if len(job.Prev) > 1 {
+			var deps []string
+			for _, dep := range job.Prev {
+				deps = append(deps, dep.ID)
+			}
+			columns = append(columns, "after "+strings.Join(deps, ", "))
+		}
+		fmt.Fprintln(w, strings.Join(columns, "\t"))
+	}
+	w.Flush()
+}
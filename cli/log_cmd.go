@@ -2,7 +2,6 @@ package cli
 
 import (
 	"github.com/spf13/cobra"
-	"log"
 	"net/url"
 	"os"
 	"strconv"
@@ -24,7 +23,7 @@ func NewLogCmd(context Context) *cobra.Command {
 			if len(args) == 1 {
 				u, err := url.Parse(args[0])
 				if err != nil {
-					log.Fatal(err)
+					fatal(err)
 				}
 				query := u.Query()
 				unparsedExecID = query.Get("execid")
@@ -36,24 +35,35 @@ func NewLogCmd(context Context) *cobra.Command {
 
 			execID, err := strconv.ParseInt(unparsedExecID, 10, 64)
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
 
-			lastOffset, err := client.FetchLogsUntilEnd(execID, projectID, 0, os.Stdout)
+			ctx, stop := interruptContext()
+			defer stop()
+
+			offset, err := client.FetchLogsUntilEndContext(ctx, execID, projectID, 0, os.Stdout)
 			if err != nil {
-				log.Fatal(err)
+				if ctx.Err() != nil {
+					return
+				}
+				fatal(err)
 			}
 
-			offset := lastOffset
 			if follow {
 				ticker := time.NewTicker(time.Second * 2)
+				defer ticker.Stop()
 
 				for {
 					select {
+					case <-ctx.Done():
+						return
 					case <-ticker.C:
-						offset, err = client.FetchLogsUntilEnd(execID, projectID, offset, os.Stdout)
+						offset, err = client.FetchLogsUntilEndContext(ctx, execID, projectID, offset, os.Stdout)
 						if err != nil {
-							panic(err)
+							if ctx.Err() != nil {
+								return
+							}
+							fatal(err)
 						}
 					}
 				}
@@ -61,7 +71,7 @@ func NewLogCmd(context Context) *cobra.Command {
 		},
 	}
 
-	logCmd.Flags().BoolP("follow", "f", false, "follow log, indefinitely updates every 2 seconds")
+	logCmd.Flags().BoolP("follow", "f", false, "follow log, updates every 2 seconds until interrupted with Ctrl-C")
 
 	return logCmd
 }
@@ -7,27 +7,71 @@ import (
 )
 
 func NewLoginCmd(context Context) *cobra.Command {
-	return &cobra.Command{
-		Use:   "login",
+	cmd := &cobra.Command{
+		Use:   "login <url> [<username> <password>]",
 		Short: "create a new session",
-		Long: `Creates a new session using the given URL, username, and password
-and outputs the Azkaban session ID and Host to be used to set these values as
-environment variables. Ideally you run this command in a subshell, like so:
+		Long: `Creates a new session using the given URL, username, and password. The
+session is stored per host under the harbormaster config directory, so
+subsequent invocations with --host or HARBORMASTER_HOST set use it.
+
+For backwards compatibility login also outputs the Azkaban session ID and Host
+to be used to set these values as environment variables:
 
 # $(harbormaster login <url> <username> <password>)
 
-This will automatically set these values for your current shell and subsequent
-invocations of harbormaster will use these values.`,
-		Args: cobra.ExactArgs(3),
+To log in again automatically once the session expired, pass a credential
+helper with --credential-helper or HARBORMASTER_CREDENTIAL_HELPER; in that case
+username and password can be omitted. With --remember the credential helper is
+stored with the session, so it doesn't have to be passed again. Passwords are
+never stored:
+
+# harbormaster --credential-helper 'echo username=$USER; echo password=$(pass azkaban)' login --remember <url>`,
+		Args: cobra.RangeArgs(1, 3),
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := azkaban.ConnectWithUsernameAndPassword(args[0], args[1], args[2])
+			host := args[0]
+			remember, _ := cmd.Flags().GetBool("remember")
+			if remember && credentialHelperCommand(&context) == "" {
+				usageError("--remember requires a credential helper, passwords are never stored")
+			}
+
+			var credentials azkaban.Credentials
+			switch {
+			case len(args) == 3:
+				credentials = azkaban.Credentials{Username: args[1], Password: args[2]}
+			case credentialHelperCommand(&context) != "":
+				var err error
+				credentials, err = credentialHelper(credentialHelperCommand(&context), host)()
+				if err != nil {
+					fatal(err)
+				}
+			default:
+				usageError("pass username and password, or a credential helper")
+			}
+
+			client, err := azkaban.ConnectWithUsernameAndPassword(host, credentials.Username, credentials.Password)
 			if err != nil {
-				fmt.Println(err.Error())
-				return
+				fatal(err)
+			}
+
+			store, err := loadSessionStore()
+			if err != nil {
+				fatal(err)
+			}
+			if err := store.SaveSessionID(host, client.SessionID); err != nil {
+				fatal(err)
+			}
+			if remember {
+				if err := store.SaveCredentialHelper(host, credentialHelperCommand(&context)); err != nil {
+					fatal(err)
+				}
 			}
 
 			fmt.Printf("export %s=%s\n", HarbormasterSessionID, client.SessionID)
-			fmt.Printf("export %s=%s\n", HarbormasterHost, args[0])
+			fmt.Printf("export %s=%s\n", HarbormasterHost, host)
 		},
 	}
+
+	cmd.Flags().Bool("remember", false, "store the credential helper to log in again when the session expired")
+
+	return cmd
 }
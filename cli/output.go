@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/fatih/color"
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/format"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputTable    = "table"
+	outputJSON     = "json"
+	outputYAML     = "yaml"
+	outputCSV      = "csv"
+	outputTSV      = "tsv"
+	outputTemplate = "template"
+	// outputGoTemplate is selected with go-template=<template> or go-template-file=<path>
+	outputGoTemplate = "go-template"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML, outputCSV, outputTSV, outputTemplate, "go-template=...", "go-template-file=..."}
+
+// output writes the result of a command in the format selected with --output. table and go-template work on the typed
+// values harbormaster got from Azkaban, everything else is built from view structs whose json tags define the
+// documented schema; see the README.
+type output struct {
+	format   string
+	template *template.Template
+	writer   io.Writer
+}
+
+// addOutputFlags registers --output and --template, see outputFromFlags
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", outputTable, fmt.Sprintf("output format, valid are [%s]", strings.Join(outputFormats, ", ")))
+	cmd.Flags().String("template", "", "Go template executed for each row with -o template, e.g. '{{.FlowID}} {{.Status}}'")
+}
+
+func outputFromFlags(cmd *cobra.Command) (output, error) {
+	o := output{writer: os.Stdout}
+	o.format, _ = cmd.Flags().GetString("output")
+
+	switch {
+	case strings.HasPrefix(o.format, outputGoTemplate+"="):
+		text := strings.TrimPrefix(o.format, outputGoTemplate+"=")
+		o.format = outputGoTemplate
+		var err error
+		if o.template, err = template.New("output").Funcs(templateFuncs).Parse(text); err != nil {
+			return o, err
+		}
+		return o, nil
+	case strings.HasPrefix(o.format, outputGoTemplate+"-file="):
+		path := strings.TrimPrefix(o.format, outputGoTemplate+"-file=")
+		o.format = outputGoTemplate
+		text, err := ioutil.ReadFile(path)
+		if err != nil {
+			return o, err
+		}
+		if o.template, err = template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(text)); err != nil {
+			return o, err
+		}
+		return o, nil
+	}
+
+	switch o.format {
+	case outputTable, outputJSON, outputYAML, outputCSV, outputTSV:
+	case outputTemplate:
+		text, _ := cmd.Flags().GetString("template")
+		if text == "" {
+			return o, fmt.Errorf("-o %s requires --template", outputTemplate)
+		}
+		var err error
+		if o.template, err = template.New("row").Funcs(templateFuncs).Parse(text); err != nil {
+			return o, err
+		}
+	default:
+		return o, fmt.Errorf("unknown output format %q, valid are %s", o.format, strings.Join(outputFormats, ", "))
+	}
+
+	return o, nil
+}
+
+// isTable returns true if the human readable table output was selected
+func (o output) isTable() bool {
+	return o.format == outputTable
+}
+
+// write writes rows, either a slice of view structs or a single view struct, in the selected format. data holds the
+// typed values go-templates are executed with, table renders the human readable output.
+func (o output) write(rows interface{}, data interface{}, table func(w io.Writer)) error {
+	switch o.format {
+	case outputGoTemplate:
+		return o.template.Execute(o.writer, data)
+	case outputJSON:
+		encoder := json.NewEncoder(o.writer)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	case outputYAML:
+		return o.writeYAML(rows)
+	case outputCSV:
+		return o.writeSeparated(rows, ',')
+	case outputTSV:
+		return o.writeSeparated(rows, '\t')
+	case outputTemplate:
+		return o.writeTemplate(rows)
+	default:
+		table(o.writer)
+		return nil
+	}
+}
+
+// writeYAML writes rows with the same field names and order as the JSON output.
+func (o output) writeYAML(rows interface{}) error {
+	b, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	v, err := jsonToYAML(decoder)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = o.writer.Write(out)
+	return err
+}
+
+// jsonToYAML reads the next JSON value from the decoder, keeping the order of object keys.
+func jsonToYAML(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			list := []interface{}{}
+			for decoder.More() {
+				v, err := jsonToYAML(decoder)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			_, err := decoder.Token()
+			return list, err
+		}
+
+		object := yaml.MapSlice{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			v, err := jsonToYAML(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, yaml.MapItem{Key: key, Value: v})
+		}
+		_, err := decoder.Token()
+		return object, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// writeSeparated writes rows as CSV or TSV with a header of the rows' JSON field names. Nested values are written as
+// JSON.
+func (o output) writeSeparated(rows interface{}, separator rune) error {
+	rows = asSlice(rows)
+	columns := jsonFieldNames(reflect.TypeOf(rows).Elem())
+
+	b, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	var records []map[string]json.RawMessage
+	if err := json.Unmarshal(b, &records); err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(o.writer)
+	w.Comma = separator
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	for _, record := range records {
+		var line []string
+		for _, column := range columns {
+			line = append(line, csvValue(record[column]))
+		}
+		if err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func csvValue(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
+
+// jsonFieldNames returns the JSON names of the fields of the given struct type in order.
+func jsonFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous && name == "" {
+			names = append(names, jsonFieldNames(field.Type)...)
+			continue
+		}
+		if name == "-" || field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// writeTemplate executes the template for each row, each followed by a newline.
+func (o output) writeTemplate(rows interface{}) error {
+	v := reflect.ValueOf(asSlice(rows))
+	for i := 0; i < v.Len(); i++ {
+		if err := o.template.Execute(o.writer, v.Index(i).Interface()); err != nil {
+			return err
+		}
+		fmt.Fprintln(o.writer)
+	}
+	return nil
+}
+
+// asSlice wraps a single view in a slice
+func asSlice(rows interface{}) interface{} {
+	v := reflect.ValueOf(rows)
+	if v.Kind() == reflect.Slice {
+		return rows
+	}
+	slice := reflect.MakeSlice(reflect.SliceOf(v.Type()), 1, 1)
+	slice.Index(0).Set(v)
+	return slice.Interface()
+}
+
+// templateFuncs are available in go-templates and -o template in addition to the builtin functions
+var templateFuncs = template.FuncMap{
+	"humanizeTime": humanizeTime,
+	"duration":     format.DurationHumanReadable,
+	"color":        colorize,
+}
+
+// humanizeTime formats any of the time types in Azkaban responses relative to now, e.g. "3 hours ago"
+func humanizeTime(t interface{}) (string, error) {
+	switch t := t.(type) {
+	case time.Time:
+		return humanize.Time(t), nil
+	case *time.Time:
+		if t == nil {
+			return "", nil
+		}
+		return humanize.Time(*t), nil
+	case azkaban.AzkabanTimestamp:
+		return humanize.Time(t.Time()), nil
+	case azkaban.AzkabanStringTime:
+		return humanize.Time(t.Time()), nil
+	}
+	return "", fmt.Errorf("humanizeTime: unsupported type %T", t)
+}
+
+var colors = map[string]func(string, ...interface{}) string{
+	"black":   color.BlackString,
+	"red":     color.RedString,
+	"green":   color.GreenString,
+	"yellow":  color.YellowString,
+	"blue":    color.BlueString,
+	"magenta": color.MagentaString,
+	"cyan":    color.CyanString,
+	"white":   color.WhiteString,
+}
+
+// colorize colors the given value, e.g. {{color "red" .Status}}. Colors are disabled if stdout is not a terminal.
+func colorize(name string, v interface{}) (string, error) {
+	colorFunc, ok := colors[name]
+	if !ok {
+		return "", fmt.Errorf("color: unknown color %q", name)
+	}
+	return colorFunc("%s", fmt.Sprint(v)), nil
+}
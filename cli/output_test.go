@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"text/template"
+	"time"
+)
+
+type testRow struct {
+	Name   string     `json:"name"`
+	Count  int        `json:"count"`
+	When   *time.Time `json:"when"`
+	Tags   []string   `json:"tags"`
+	hidden string
+}
+
+func TestOutputWrite(t *testing.T) {
+	when := time.Date(2019, time.August, 1, 10, 0, 0, 0, time.UTC)
+	rows := []testRow{
+		{Name: "a", Count: 1, When: &when, Tags: []string{"x", "y"}},
+		{Name: "b, c", Count: 2},
+	}
+
+	tests := []struct {
+		format   string
+		rows     interface{}
+		expected string
+	}{
+		{
+			format:   outputJSON,
+			rows:     rows[1:],
+			expected: "[\n  {\n    \"name\": \"b, c\",\n    \"count\": 2,\n    \"when\": null,\n    \"tags\": null\n  }\n]\n",
+		},
+		{
+			format:   outputYAML,
+			rows:     rows,
+			expected: "- name: a\n  count: 1\n  when: \"2019-08-01T10:00:00Z\"\n  tags:\n  - x\n  - \"y\"\n- name: b, c\n  count: 2\n  when: null\n  tags: null\n",
+		},
+		{
+			format:   outputCSV,
+			rows:     rows,
+			expected: "name,count,when,tags\na,1,2019-08-01T10:00:00Z,\"[\"\"x\"\",\"\"y\"\"]\"\n\"b, c\",2,,\n",
+		},
+		{
+			format:   outputTSV,
+			rows:     []testRow{},
+			expected: "name\tcount\twhen\ttags\n",
+		},
+		{
+			format:   outputCSV,
+			rows:     rows[0],
+			expected: "name,count,when,tags\na,1,2019-08-01T10:00:00Z,\"[\"\"x\"\",\"\"y\"\"]\"\n",
+		},
+		{
+			format:   outputTemplate,
+			rows:     rows,
+			expected: "a: 1\nb, c: 2\n",
+		},
+	}
+
+	for _, test := range tests {
+		var buf bytes.Buffer
+		o := output{format: test.format, writer: &buf, template: template.Must(template.New("row").Parse("{{.Name}}: {{.Count}}"))}
+		if err := o.write(test.rows, nil, func(w io.Writer) { t.Error("unexpected table output") }); err != nil {
+			t.Errorf("%s: %s", test.format, err)
+			continue
+		}
+		if buf.String() != test.expected {
+			t.Errorf("%s: expected\n%q\ngot\n%q", test.format, test.expected, buf.String())
+		}
+	}
+}
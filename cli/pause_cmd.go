@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/spf13/cobra"
+)
+
+func NewPauseCmd(context Context) *cobra.Command {
+	return newExecutionActionCmd(context, executionAction{
+		use:   "pause [execid|execution url]...",
+		short: "pause running executions",
+		long: `Pauses one or more running executions. Jobs that are already running will
+finish, but no new jobs are started until the execution is resumed. Executions
+can be given as execution IDs or execution URLs, or with --flow:
+
+# harbormaster pause 12345
+# harbormaster -p <project> pause --flow /^backfill_/`,
+		verb:    "paused",
+		perform: (*azkaban.Client).PauseExecution,
+	})
+}
+
+func NewResumeCmd(context Context) *cobra.Command {
+	return newExecutionActionCmd(context, executionAction{
+		use:   "resume [execid|execution url]...",
+		short: "resume paused executions",
+		long: `Resumes one or more paused executions. Executions can be given as execution
+IDs or execution URLs, or with --flow:
+
+# harbormaster resume 12345
+# harbormaster -p <project> resume --flow /^backfill_/`,
+		verb:    "resumed",
+		perform: (*azkaban.Client).ResumeExecution,
+	})
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+)
+
+func NewProfileCmd(context Context) *cobra.Command {
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "manage Azkaban environments configured in the config file",
+		Long: `Profiles are named Azkaban environments in ~/.config/harbormaster/config.yaml
+(or $HARBORMASTER_CONFIG_DIR/config.yaml):
+
+current-profile: prod
+profiles:
+  prod:
+    host: https://azkaban.example.com
+    project: warehouse
+    auth: credential-helper
+    credential-helper: echo username=$USER; echo password=$(pass azkaban/prod)
+    timeout: 1m
+  staging:
+    host: https://azkaban-staging.example.com
+
+auth is one of session, stored-credentials, or credential-helper. Select a
+profile with --profile or HARBORMASTER_PROFILE, or make it the default with
+"harbormaster profile use". Flags and environment variables override the
+profile's settings.`,
+	}
+
+	profileCmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"l"},
+		Short:   "list profiles",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fatal(err)
+			}
+
+			current := context.ProfileName()
+			w := new(tabwriter.Writer)
+			w.Init(os.Stdout, 4, 4, 2, ' ', 0)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", "", "Profile", "Host", "Project")
+			for _, name := range cfg.ProfileNames() {
+				marker := ""
+				if name == current {
+					marker = "*"
+				}
+				p := cfg.Profiles[name]
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", marker, name, p.Host, p.Project)
+			}
+			w.Flush()
+		},
+	})
+
+	profileCmd.AddCommand(&cobra.Command{
+		Use:   "use <profile>",
+		Short: "make a profile the default",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fatal(err)
+			}
+			if _, err := cfg.Profile(args[0]); err != nil {
+				fatal(err)
+			}
+
+			cfg.CurrentProfile = args[0]
+			if err := cfg.save(); err != nil {
+				fatal(err)
+			}
+			fmt.Printf("using profile %s\n", args[0])
+		},
+	})
+
+	profileCmd.AddCommand(&cobra.Command{
+		Use:   "show [profile]",
+		Short: "show a profile, by default the selected one",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := loadConfig()
+			if err != nil {
+				fatal(err)
+			}
+
+			name := context.ProfileName()
+			if len(args) == 1 {
+				name = args[0]
+			}
+			if name == "" {
+				usageError("no profile selected")
+			}
+			p, err := cfg.Profile(name)
+			if err != nil {
+				fatal(err)
+			}
+
+			auth := p.Auth
+			if auth == "" {
+				auth = "default"
+			}
+			fmt.Printf("%-20s %s\n", "Profile:", name)
+			fmt.Printf("%-20s %s\n", "Host:", p.Host)
+			fmt.Printf("%-20s %s\n", "Project:", p.Project)
+			fmt.Printf("%-20s %s\n", "Auth:", auth)
+			if p.CredentialHelper != "" {
+				fmt.Printf("%-20s %s\n", "Credential helper:", p.CredentialHelper)
+			}
+			if p.Timeout != "" {
+				fmt.Printf("%-20s %s\n", "Timeout:", p.Timeout)
+			}
+			if p.RateLimit > 0 {
+				fmt.Printf("%-20s %v requests/s\n", "Rate limit:", p.RateLimit)
+			}
+		},
+	})
+
+	return profileCmd
+}
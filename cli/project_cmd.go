@@ -0,0 +1,380 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/projectdir"
+	"github.com/spf13/cobra"
+)
+
+func NewProjectCmd(context Context) *cobra.Command {
+	projectCmd := &cobra.Command{
+		Use:   "project",
+		Short: "create, delete, and deploy projects",
+	}
+
+	projectCmd.AddCommand(newProjectCreateCmd(context))
+	projectCmd.AddCommand(newProjectDeleteCmd(context))
+	projectCmd.AddCommand(newProjectUploadCmd(context))
+	projectCmd.AddCommand(newProjectDiffCmd(context))
+	projectCmd.AddCommand(newProjectVersionsCmd(context))
+	projectCmd.AddCommand(newProjectDownloadCmd(context))
+
+	return projectCmd
+}
+
+func newProjectCreateCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create <project>",
+		Short: "create an empty project",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			description, _ := cmd.Flags().GetString("description")
+			// Azkaban doesn't accept projects without description
+			if description == "" {
+				description = args[0]
+			}
+
+			if err := context.Client().CreateProject(args[0], description); err != nil {
+				fatal(err)
+			}
+			fmt.Printf("created project %s\n", args[0])
+		},
+	}
+
+	cmd.Flags().StringP("description", "d", "", "description of the project, defaults to its name")
+
+	return cmd
+}
+
+func newProjectDeleteCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <project>",
+		Short: "delete a project with all its flows, executions, and schedules",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if yes, _ := cmd.Flags().GetBool("yes"); !yes {
+				usageError("deleting project %s can't be undone, pass --yes to delete it", args[0])
+			}
+
+			if err := context.Client().DeleteProject(args[0]); err != nil {
+				fatal(err)
+			}
+			fmt.Printf("deleted project %s\n", args[0])
+		},
+	}
+
+	cmd.Flags().Bool("yes", false, "confirm deleting the project")
+
+	return cmd
+}
+
+func newProjectUploadCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upload <dir|zip>",
+		Short: "upload a project zip, or a directory zipped on the fly, as new version of the current project",
+		Long: `Uploads a new version of the current project. Given a directory, harbormaster
+zips it, leaving out hidden files like .git:
+
+# harbormaster -p <project> project upload ./flows --validate
+# harbormaster -p <project> project upload build/flows.zip --validate`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			project := context.Project()
+			if project == "" {
+				usageError("no project given, pass --project or use a profile with a project")
+			}
+
+			if validate, _ := cmd.Flags().GetBool("validate"); validate {
+				jobTypes, _ := cmd.Flags().GetStringSlice("job-type")
+				project, problems, err := validateProject(args[0], jobTypes)
+				if err != nil {
+					fatal(err)
+				}
+				if len(problems) > 0 {
+					printProblems(os.Stdout, project, problems)
+				}
+				if countProblems(problems, projectdir.Error) > 0 {
+					log.Printf("not uploading %s because of validation errors", args[0])
+					os.Exit(exitError)
+				}
+			}
+
+			zip, err := openProjectZip(args[0])
+			if err != nil {
+				fatal(err)
+			}
+
+			result, err := context.Client().UploadProjectZip(project, zip)
+			if err != nil {
+				fatal(err)
+			}
+			fmt.Printf("uploaded %s to project %s, version %s\n", args[0], project, result.Version)
+			if result.Warnings != "" {
+				fmt.Println(result.Warnings)
+			}
+		},
+	}
+
+	cmd.Flags().Bool("validate", false, "validate the project directory or zip first and don't upload it if there are errors, see validate")
+	addJobTypeFlag(cmd)
+
+	return cmd
+}
+
+func newProjectDiffCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <dir>",
+		Short: "compare a project directory with the flows deployed in the current project",
+		Long: `Compares the flows, job dependencies, and job properties of a project directory
+with the ones deployed in the current project. + marks what uploading the
+directory would add, - what it would remove, and ~ what it would change. Job
+properties changed in Azkaban's web UI are not compared:
+
+# harbormaster -p <project> project diff ./flows --exit-code`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			project := context.Project()
+			if project == "" {
+				usageError("no project given, pass --project or use a profile with a project")
+			}
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			local, err := projectdir.Load(args[0])
+			if err != nil {
+				fatal(err)
+			}
+			deployed, err := fetchDeployedFlows(context.Client(), project, concurrencyFromFlags(cmd))
+			if err != nil {
+				fatal(err)
+			}
+
+			changes := projectdir.Diff(local, deployed)
+			views := []changeView{}
+			for _, c := range changes {
+				views = append(views, newChangeView(c))
+			}
+			err = out.write(views, changes, func(w io.Writer) {
+				for _, c := range changes {
+					fmt.Fprintln(w, c)
+				}
+				if len(changes) == 0 {
+					fmt.Fprintf(w, "%s matches project %s\n", args[0], project)
+				}
+			})
+			if err != nil {
+				fatal(err)
+			}
+
+			if exitCode, _ := cmd.Flags().GetBool("exit-code"); exitCode && len(changes) > 0 {
+				os.Exit(exitError)
+			}
+		},
+	}
+
+	addOutputFlags(cmd)
+	addConcurrencyFlag(cmd)
+	cmd.Flags().Bool("exit-code", false, "exit with 1 if there are differences")
+
+	return cmd
+}
+
+func newProjectVersionsCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "list the uploaded versions of the current project, most recent first",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			project := context.Project()
+			if project == "" {
+				usageError("no project given, pass --project or use a profile with a project")
+			}
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			versions, err := context.Client().ProjectVersions(project)
+			if err != nil {
+				fatal(err)
+			}
+
+			views := []projectVersionView{}
+			for _, v := range versions {
+				views = append(views, newProjectVersionView(project, v))
+			}
+			err = out.write(views, versions, func(writer io.Writer) {
+				w := new(tabwriter.Writer)
+				w.Init(writer, 4, 4, 2, ' ', 0)
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", "Version", "Uploaded By", "When", "Upload Time", "Message")
+				for _, v := range versions {
+					fmt.Fprintf(
+						w,
+						"%d \t%s \t%s \t%s \t%s\n",
+						v.Version,
+						v.UploadUser,
+						humanize.Time(v.UploadTime),
+						v.UploadTime.Format(time.RFC1123),
+						v.Message,
+					)
+				}
+				w.Flush()
+			})
+			if err != nil {
+				fatal(err)
+			}
+		},
+	}
+
+	addOutputFlags(cmd)
+
+	return cmd
+}
+
+func newProjectDownloadCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "download [version]",
+		Short: "download the zip file of a version of the current project, the latest one by default",
+		Long: `Downloads the zip file of a version of the current project, e.g. to roll back
+to an earlier version. Version numbers are counted from the project log, see
+project versions. Without a version Azkaban serves the latest one, which is
+saved under the name it was uploaded with:
+
+# harbormaster -p <project> project versions
+# harbormaster -p <project> project download 11
+# harbormaster -p <project> project upload <project>-11.zip`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			project := context.Project()
+			if project == "" {
+				usageError("no project given, pass --project or use a profile with a project")
+			}
+			version := 0
+			if len(args) > 0 {
+				var err error
+				if version, err = strconv.Atoi(args[0]); err != nil || version < 1 {
+					usageError("invalid version %q", args[0])
+				}
+			}
+
+			zip, err := context.Client().DownloadProject(project, version)
+			if err != nil {
+				fatal(err)
+			}
+			file, _ := cmd.Flags().GetString("file")
+			if file == "-" {
+				os.Stdout.Write(zip.Content)
+				return
+			}
+			if file == "" {
+				file = downloadFileName(project, version, zip)
+			}
+			if err := ioutil.WriteFile(file, zip.Content, 0644); err != nil {
+				fatal(err)
+			}
+			if version == 0 {
+				fmt.Printf("downloaded the latest version of project %s to %s\n", project, file)
+				return
+			}
+			fmt.Printf("downloaded version %d of project %s to %s\n", version, project, file)
+		},
+	}
+
+	cmd.Flags().StringP("file", "f", "", "file to write the zip to, - for stdout, defaults to <project>-<version>.zip, or the name Azkaban serves the latest version with")
+
+	return cmd
+}
+
+// downloadFileName is the file a downloaded project zip is saved to by default. Only the version that was asked for
+// goes into the name, the latest version keeps the name Azkaban served it with since its number isn't known.
+func downloadFileName(project string, version int, zip azkaban.ProjectZip) string {
+	switch {
+	case version > 0:
+		return fmt.Sprintf("%s-%d.zip", project, version)
+	case zip.FileName != "":
+		return zip.FileName
+	}
+	return project + "-latest.zip"
+}
+
+// fetchDeployedFlows fetches the flows of the given project with their job graphs and job properties.
+func fetchDeployedFlows(client *azkaban.Client, project string, concurrency int) ([]projectdir.DeployedFlow, error) {
+	flows, err := client.ListFlows(project)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed := make([]projectdir.DeployedFlow, len(flows))
+	err = azkaban.ForEachConcurrently(len(flows), concurrency, func(i int) error {
+		jobList, err := client.FlowJobList(project, flows[i].FlowID)
+		if err != nil {
+			return err
+		}
+		deployed[i] = projectdir.DeployedFlow{
+			ID:         flows[i].FlowID,
+			Jobs:       jobList.Nodes,
+			Properties: make(map[string]map[string]string),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	type flowJob struct {
+		flow int
+		job  string
+	}
+	var jobs []flowJob
+	for i, f := range deployed {
+		for _, j := range f.Jobs {
+			jobs = append(jobs, flowJob{flow: i, job: j.ID})
+		}
+	}
+	infos := make([]azkaban.JobInfo, len(jobs))
+	err = azkaban.ForEachConcurrently(len(jobs), concurrency, func(i int) error {
+		info, err := client.JobInfo(project, deployed[jobs[i].flow].ID, jobs[i].job)
+		infos[i] = info
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, j := range jobs {
+		deployed[j.flow].Properties[j.job] = infos[i].GeneralParams
+	}
+
+	return deployed, nil
+}
+
+// openProjectZip returns the given zip file, or a zip of the given directory.
+func openProjectZip(path string) (io.Reader, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		content, err := ioutil.ReadFile(path)
+		return bytes.NewReader(content), err
+	}
+
+	var buf bytes.Buffer
+	if err := projectdir.Zip(path, &buf); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
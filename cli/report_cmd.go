@@ -5,8 +5,7 @@ import (
 	"github.com/ilikeorangutans/harbormaster/azkaban"
 	"github.com/ilikeorangutans/harbormaster/format"
 	"github.com/spf13/cobra"
-	"log"
-	"os"
+	"io"
 	"text/tabwriter"
 	"time"
 )
@@ -17,55 +16,62 @@ func NewReportCmd(context Context) *cobra.Command {
 		Aliases: []string{"aet"},
 		Short:   "average execution time ",
 		Run: func(cmd *cobra.Command, args []string) {
+			// --format is the deprecated predecessor of --output
+			if f, _ := cmd.Flags().GetString("format"); f != "" {
+				if f == "console" {
+					f = outputTable
+				}
+				cmd.Flags().Set("output", f)
+			}
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			spinnerStatus := startSpinner(out)
+
 			project := azkaban.Project{Name: context.Project()}
 			flows, err := context.Context().Flows().ListFlows(project, predicateFromArgs(args, 0))
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
+			spinnerStatus.SetTotal(len(flows))
 
-			var data []execReportData
+			data := make([]execReportData, len(flows))
 			execRepo := context.Context().Executions()
-
-			for _, f := range flows {
-				executions, err := execRepo.ListExecutions(project, f, azkaban.TenMostRecent)
+			err = azkaban.ForEachConcurrently(len(flows), concurrencyFromFlags(cmd), func(i int) error {
+				executions, err := execRepo.ListExecutions(project, flows[i], azkaban.TenMostRecent)
 				if err != nil {
-					log.Fatal(err)
-				}
-
-				execData := execReportData{
-					FlowID: f.FlowID,
+					return err
 				}
-
-				var totalTime time.Duration = 0.0
-				for _, exec := range executions {
-					execData.TotalCount++
-					if !exec.IsSuccess() {
-						continue
-					}
-					execData.SuccessCount++
-
-					totalTime += exec.Duration()
-				}
-
-				if execData.SuccessCount > 0 {
-					execData.AverageTime = totalTime / time.Duration(execData.SuccessCount)
-				}
-				data = append(data, execData)
-			}
-
-			formatter := consoleFormatter
-			formatStr, err := cmd.Flags().GetString("formatStr")
+				data[i] = newExecReportData(flows[i].FlowID, executions)
+				spinnerStatus.AddProgress()
+				return nil
+			})
+			spinnerStatus.Stop()
 			if err != nil {
-				log.Fatal(err)
+				fatal(err)
 			}
-			if formatStr == "tsv" {
-				formatter = tsvFormatter
+
+			views := []averageExecutionTimeView{}
+			for _, d := range data {
+				views = append(views, averageExecutionTimeView{
+					FlowID:         d.FlowID,
+					SuccessCount:   d.SuccessCount,
+					FailureCount:   d.TotalCount - d.SuccessCount,
+					AverageSeconds: int64(d.AverageTime.Seconds()),
+				})
 			}
 
-			formatter(data)
+			if err := out.write(views, data, func(w io.Writer) { consoleFormatter(w, data) }); err != nil {
+				fatal(err)
+			}
 		},
 	}
-	averageExecutionTimeCmd.Flags().StringP("format", "f", "console", "format to display data in, valid are [console, tsv]")
+	addConcurrencyFlag(averageExecutionTimeCmd)
+	addOutputFlags(averageExecutionTimeCmd)
+	averageExecutionTimeCmd.Flags().StringP("format", "f", "", "format to display data in, valid are [console, tsv]")
+	averageExecutionTimeCmd.Flags().MarkDeprecated("format", "use --output instead")
 
 	reportCmd := &cobra.Command{
 		Use: "report",
@@ -75,22 +81,6 @@ func NewReportCmd(context Context) *cobra.Command {
 	return reportCmd
 }
 
-func formatDuration(d time.Duration) string {
-	hours := 0
-	if d.Hours() >= 1.0 {
-		hours = int(d.Hours())
-	}
-	minutes := 0
-	if d.Minutes() > 0 {
-		minutes = int(d.Minutes()) % 60
-	}
-	seconds := 0
-	if d.Seconds() > 0 {
-		seconds = int(d.Seconds()) % 60
-	}
-	return fmt.Sprintf("%d:%d:%d", hours, minutes, seconds)
-}
-
 type execReportData struct {
 	FlowID       string
 	SuccessCount int
@@ -98,9 +88,31 @@ type execReportData struct {
 	AverageTime  time.Duration
 }
 
-func consoleFormatter(data []execReportData) {
+func newExecReportData(flowID string, executions azkaban.Executions) execReportData {
+	execData := execReportData{
+		FlowID: flowID,
+	}
+
+	var totalTime time.Duration = 0.0
+	for _, exec := range executions {
+		execData.TotalCount++
+		if !exec.IsSuccess() {
+			continue
+		}
+		execData.SuccessCount++
+
+		totalTime += exec.Duration()
+	}
+
+	if execData.SuccessCount > 0 {
+		execData.AverageTime = totalTime / time.Duration(execData.SuccessCount)
+	}
+	return execData
+}
+
+func consoleFormatter(writer io.Writer, data []execReportData) {
 	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 4, 4, 2, ' ', 0)
+	w.Init(writer, 4, 4, 2, ' ', 0)
 	fmt.Fprintf(
 		w,
 		"%s\t%s\t%s\t%s\n",
@@ -122,10 +134,3 @@ func consoleFormatter(data []execReportData) {
 	w.Flush()
 
 }
-
-func tsvFormatter(data []execReportData) {
-	fmt.Printf("FlowID \tSuccess Count \t Average Time\n")
-	for _, d := range data {
-		fmt.Printf("%s \t %4d \t%s\n", d.FlowID, d.SuccessCount, formatDuration(d.AverageTime))
-	}
-}
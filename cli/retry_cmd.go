@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func NewRetryCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "retry <execid|execution url>",
+		Short: "rerun only the failed jobs of an execution",
+		Long: `Submits a new execution of the flow of the given execution, using the same
+flow parameters and options, with every job that already succeeded disabled.
+
+# harbormaster retry 12345`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			execID, err := parseExecutionID(args[0])
+			if err != nil {
+				fatal(err)
+			}
+
+			client := context.Client()
+			plan, err := client.PlanRetry(execID)
+			if err != nil {
+				fatal(err)
+			}
+
+			fmt.Printf("%-16s %s %s\n", "Flow:", plan.Project, plan.Flow)
+			fmt.Printf("%-16s %s\n", "Disabled jobs:", strings.Join(plan.Options.DisabledJobs, ", "))
+
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				return
+			}
+
+			result, err := client.ExecuteFlow(plan.Project, plan.Flow, plan.Options)
+			if err != nil {
+				fatal(err)
+			}
+			fmt.Printf("submitted execution %d\n", result.ExecutionID)
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "only show which jobs would be disabled")
+
+	return cmd
+}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"github.com/ilikeorangutans/harbormaster/azkaban"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -9,6 +10,12 @@ const (
 	HarbormasterSessionID = "HARBORMASTER_SESSION_ID"
 	HarbormasterHost      = "HARBORMASTER_HOST"
 	HarbormasterProject   = "HARBORMASTER_PROJECT"
+	HarbormasterProfile   = "HARBORMASTER_PROFILE"
+
+	HarbormasterCredentialHelper = "HARBORMASTER_CREDENTIAL_HELPER"
+	HarbormasterRateLimit        = "HARBORMASTER_RATE_LIMIT"
+	HarbormasterTimeout          = "HARBORMASTER_TIMEOUT"
+	HarbormasterRetries          = "HARBORMASTER_RETRIES"
 )
 
 func NewRootCmd() *cobra.Command {
@@ -31,6 +38,29 @@ func NewRootCmd() *cobra.Command {
 	viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("host"))
 	viper.BindEnv("host", HarbormasterHost)
 
+	rootCmd.PersistentFlags().String("profile", "", "profile from the config file to use")
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	viper.BindEnv("profile", HarbormasterProfile)
+
+	rootCmd.PersistentFlags().String("credential-helper", "", "shell command printing username=... and password=... lines, used to log in again when the session expired")
+	viper.BindPFlag("credential-helper", rootCmd.PersistentFlags().Lookup("credential-helper"))
+	viper.BindEnv("credential-helper", HarbormasterCredentialHelper)
+
+	rootCmd.PersistentFlags().Float64("rate-limit", 0, "maximum number of requests per second sent to Azkaban, 0 for no limit")
+	viper.BindPFlag("rate-limit", rootCmd.PersistentFlags().Lookup("rate-limit"))
+	viper.BindEnv("rate-limit", HarbormasterRateLimit)
+
+	rootCmd.PersistentFlags().Duration("timeout", 0, "HTTP timeout for requests to Azkaban, e.g. 30s or 2m, overrides the profile's timeout")
+	viper.BindPFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	viper.BindEnv("timeout", HarbormasterTimeout)
+
+	rootCmd.PersistentFlags().Int("retries", azkaban.DefaultRetryPolicy.MaxAttempts-1, "how often to retry requests when Azkaban is unreachable or answers with a server error, 0 to not retry")
+	viper.BindPFlag("retries", rootCmd.PersistentFlags().Lookup("retries"))
+	viper.BindEnv("retries", HarbormasterRetries)
+
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "log retried requests and their causes")
+	viper.BindPFlag("verbose", rootCmd.PersistentFlags().Lookup("verbose"))
+
 	viper.SetDefault("dump-responses", false)
 	rootCmd.PersistentFlags().Bool("dump-responses", false, "Dump HTTP responses from Azkaban")
 
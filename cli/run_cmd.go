@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func NewRunCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run <flow>",
+		Short: "execute a flow",
+		Long: `Submits a new execution of the given flow in the current project and prints
+the new execution ID. Flow parameters can be overridden and jobs disabled:
+
+# harbormaster -p <project> run daily_backfill --param date=2019-08-01 --disable cleanup,notify`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			options, err := executionOptionsFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			result, err := context.Client().ExecuteFlow(context.Project(), args[0], options)
+			if err != nil {
+				fatal(err)
+			}
+
+			fmt.Printf("submitted execution %d of %s %s\n", result.ExecutionID, result.Project, result.Flow)
+		},
+	}
+
+	addExecutionOptionFlags(cmd)
+
+	return cmd
+}
+
+// addExecutionOptionFlags registers flags for all execution options, see executionOptionsFromFlags
+func addExecutionOptionFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArray("param", nil, "override a flow parameter, as key=value, can be repeated")
+	cmd.Flags().StringSlice("disable", nil, "comma separated list of jobs to disable")
+	cmd.Flags().String("failure-action", "", "what to do when a job fails, valid are [finish-current, cancel-immediately, finish-possible]")
+	cmd.Flags().String("concurrent", "", "what to do if the flow is already running, valid are [ignore, pipeline, skip]")
+	cmd.Flags().Int("pipeline-level", 0, "pipeline level (1 or 2) when --concurrent=pipeline")
+	cmd.Flags().StringSlice("success-emails", nil, "comma separated list of addresses to notify on success")
+	cmd.Flags().StringSlice("failure-emails", nil, "comma separated list of addresses to notify on failure")
+	cmd.Flags().Bool("notify-failure-first", false, "notify as soon as the first job fails")
+	cmd.Flags().Bool("notify-failure-last", false, "notify when the flow finished with failures")
+}
+
+// executionOptionsFromFlags reads the flags registered by addExecutionOptionFlags
+func executionOptionsFromFlags(cmd *cobra.Command) (azkaban.ExecutionOptions, error) {
+	options := azkaban.ExecutionOptions{}
+
+	params, _ := cmd.Flags().GetStringArray("param")
+	for _, p := range params {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return options, fmt.Errorf("invalid parameter %q, expected key=value", p)
+		}
+		if options.FlowParameters == nil {
+			options.FlowParameters = make(map[string]string)
+		}
+		options.FlowParameters[kv[0]] = kv[1]
+	}
+
+	options.DisabledJobs, _ = cmd.Flags().GetStringSlice("disable")
+
+	if s, _ := cmd.Flags().GetString("failure-action"); s != "" {
+		action, err := azkaban.ParseFailureAction(s)
+		if err != nil {
+			return options, err
+		}
+		options.FailureAction = action
+	}
+
+	if s, _ := cmd.Flags().GetString("concurrent"); s != "" {
+		concurrent, err := azkaban.ParseConcurrentOption(s)
+		if err != nil {
+			return options, err
+		}
+		options.ConcurrentOption = concurrent
+	}
+	options.PipelineLevel, _ = cmd.Flags().GetInt("pipeline-level")
+
+	options.SuccessEmails, _ = cmd.Flags().GetStringSlice("success-emails")
+	options.FailureEmails, _ = cmd.Flags().GetStringSlice("failure-emails")
+	options.NotifyFailureFirst, _ = cmd.Flags().GetBool("notify-failure-first")
+	options.NotifyFailureLast, _ = cmd.Flags().GetBool("notify-failure-last")
+
+	return options, nil
+}
@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"fmt"
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/format"
+	"github.com/spf13/cobra"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+func NewScheduleCmd(context Context) *cobra.Command {
+	scheduleCmd := &cobra.Command{
+		Use:     "schedule",
+		Aliases: []string{"s"},
+		Short:   "manage flow schedules",
+	}
+
+	scheduleCmd.AddCommand(newScheduleListCmd(context))
+	scheduleCmd.AddCommand(newScheduleShowCmd(context))
+	scheduleCmd.AddCommand(newScheduleSetCmd(context))
+	scheduleCmd.AddCommand(newScheduleRemoveCmd(context))
+
+	return scheduleCmd
+}
+
+func newScheduleListCmd(context Context) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list [flow]",
+		Aliases: []string{"l"},
+		Short:   "list schedules, optionally only of the current project and flows matching a prefix or /regex/",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			schedules, err := context.Client().Schedules()
+			if err != nil {
+				fatal(err)
+			}
+
+			predicate := predicateFromArgs(args, 0)
+
+			w := new(tabwriter.Writer)
+			w.Init(os.Stdout, 4, 4, 2, ' ', 0)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", "ID", "Project", "Flow", "Schedule", "First Execution")
+			for _, s := range schedules {
+				if context.Project() != "" && s.Project != context.Project() {
+					continue
+				}
+				if !predicate(azkaban.Flow{FlowID: s.FlowID}) {
+					continue
+				}
+
+				schedule := s.CronExpression
+				if schedule == "" && s.Period > 0 {
+					schedule = "every " + format.DurationHumanReadable(time.Duration(s.Period)*time.Millisecond)
+				}
+				fmt.Fprintf(
+					w,
+					"%d\t%s\t%s\t%s\t%s\n",
+					s.ID,
+					s.Project,
+					s.FlowID,
+					schedule,
+					s.FirstSchedTime.Time().Format(time.RFC1123),
+				)
+			}
+			w.Flush()
+		},
+	}
+}
+
+func newScheduleShowCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show <flow>",
+		Short: "show the schedule of a flow and its next executions",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			count, _ := cmd.Flags().GetInt("count")
+			timezone, _ := cmd.Flags().GetString("timezone")
+			location, err := time.LoadLocation(timezone)
+			if err != nil {
+				fatal(err)
+			}
+
+			flow, proj, err := context.Context().Flows().Flow(azkaban.Project{Name: context.Project()}, args[0])
+			if err != nil {
+				fatal(err)
+			}
+
+			schedule, err := context.Client().FlowSchedule(proj.ID, flow.FlowID)
+			if err != nil {
+				fatal(err)
+			}
+			if !schedule.IsScheduled() {
+				fmt.Printf("%s %s is not scheduled\n", proj.Name, flow.FlowID)
+				return
+			}
+
+			fmt.Printf("%-16s %s\n", "Schedule ID:", schedule.ID)
+			fmt.Printf("%-16s %s\n", "Submitted by:", schedule.SubmitUser)
+			fmt.Printf("%-16s %s\n", "Schedule:", schedule.Description())
+			fmt.Printf("%-16s %s\n", "Next execution:", schedule.NextExecTime.Time().In(location).Format(time.RFC1123))
+			if err := schedule.CronExpression.Err(); err != nil {
+				fmt.Printf("%-16s %s\n", "Upcoming:", "unavailable, "+err.Error())
+			}
+			for i, t := range schedule.NextExecutions(count) {
+				label := ""
+				if i == 0 {
+					label = "Upcoming:"
+				}
+				fmt.Printf("%-16s %s\n", label, t.In(location).Format(time.RFC1123))
+			}
+		},
+	}
+
+	cmd.Flags().IntP("count", "n", 5, "how many upcoming executions to show")
+	cmd.Flags().String("timezone", "Local", "timezone to show execution times in")
+
+	return cmd
+}
+
+func newScheduleSetCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <flow>",
+		Short: "schedule a flow with a cron expression",
+		Long: `Schedules a flow in the current project with a Quartz cron expression,
+replacing any existing schedule of the flow. Quartz cron expressions have fields
+for seconds, minutes, hours, day of month, month, day of week, and optionally year:
+
+# harbormaster -p <project> schedule set <flow> --cron "0 0 2 ? * MON-FRI" --param env=prod
+
+The cron expression is evaluated in the timezone of the Azkaban server, not in
+your local one, and schedules can't have a timezone of their own. Write the
+expression in server time; --display-timezone only changes the timezone the next
+execution is shown in.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			cronExpression, _ := cmd.Flags().GetString("cron")
+			if cronExpression == "" {
+				usageError("--cron is required")
+			}
+			timezone, _ := cmd.Flags().GetString("display-timezone")
+			location, err := time.LoadLocation(timezone)
+			if err != nil {
+				fatal(err)
+			}
+
+			options, err := executionOptionsFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+
+			flow, proj, err := context.Context().Flows().Flow(azkaban.Project{Name: context.Project()}, args[0])
+			if err != nil {
+				fatal(err)
+			}
+
+			client := context.Client()
+			result, err := client.ScheduleCronFlow(proj.Name, flow.FlowID, cronExpression, options)
+			if err != nil {
+				fatal(err)
+			}
+			fmt.Printf("scheduled %s %s (schedule %d)\n", proj.Name, flow.FlowID, result.ScheduleID)
+
+			schedule, err := client.FlowSchedule(proj.ID, flow.FlowID)
+			if err != nil {
+				fatal(err)
+			}
+			if schedule.IsScheduled() {
+				fmt.Printf("%-16s %s\n", "Next execution:", schedule.NextExecTime.Time().In(location).Format(time.RFC1123))
+			}
+		},
+	}
+
+	cmd.Flags().String("cron", "", "Quartz cron expression, e.g. \"0 0 2 ? * MON-FRI\"")
+	cmd.Flags().String("display-timezone", "Local", "timezone to display the next execution in, the cron expression is evaluated in the server's timezone")
+	addExecutionOptionFlags(cmd)
+
+	return cmd
+}
+
+func newScheduleRemoveCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "remove <flow>",
+		Aliases: []string{"rm"},
+		Short:   "remove the schedule of a flow",
+		Args:    cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			scheduleID, _ := cmd.Flags().GetInt64("id")
+			if scheduleID == 0 && len(args) == 0 {
+				usageError("pass either a flow or --id")
+			}
+
+			if scheduleID > 0 {
+				if err := context.Client().RemoveSchedule(strconv.FormatInt(scheduleID, 10)); err != nil {
+					fatal(err)
+				}
+				fmt.Printf("removed schedule %d\n", scheduleID)
+				return
+			}
+
+			flow, proj, err := context.Context().Flows().Flow(azkaban.Project{Name: context.Project()}, args[0])
+			if err != nil {
+				fatal(err)
+			}
+			if err := unscheduleFlow(context.Client(), proj, flow); err != nil {
+				fatal(err)
+			}
+		},
+	}
+
+	cmd.Flags().Int64("id", 0, "remove the schedule with this ID instead of looking it up by flow")
+
+	return cmd
+}
+
+// unscheduleFlow removes the schedule of the given flow if it has one
+func unscheduleFlow(client *azkaban.Client, proj azkaban.Project, flow azkaban.Flow) error {
+	schedule, err := client.FlowSchedule(proj.ID, flow.FlowID)
+	if err != nil {
+		return err
+	}
+	if !schedule.IsScheduled() {
+		fmt.Printf("%s %s is not scheduled\n", proj.Name, flow.FlowID)
+		return nil
+	}
+
+	if err := client.RemoveSchedule(schedule.ID); err != nil {
+		return err
+	}
+	fmt.Printf("removed schedule %s of %s %s\n", schedule.ID, proj.Name, flow.FlowID)
+	return nil
+}
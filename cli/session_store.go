@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	HarbormasterConfigDir = "HARBORMASTER_CONFIG_DIR"
+)
+
+// configDir returns the directory harbormaster keeps its configuration and sessions in, usually
+// ~/.config/harbormaster. It can be overridden with HARBORMASTER_CONFIG_DIR.
+func configDir() (string, error) {
+	if dir := os.Getenv(HarbormasterConfigDir); dir != "" {
+		return dir, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "harbormaster"), nil
+}
+
+// storedSession is a session for a single Azkaban host
+type storedSession struct {
+	SessionID string    `json:"sessionId"`
+	Created   time.Time `json:"created"`
+	// CredentialHelper is the credential helper command used to log in again, only stored if the user asked to remember
+	// it. Passwords are never stored.
+	CredentialHelper string `json:"credentialHelper,omitempty"`
+}
+
+// sessionStore persists sessions keyed by Azkaban host in a file only readable by the current user.
+type sessionStore struct {
+	path     string
+	Sessions map[string]storedSession `json:"sessions"`
+}
+
+func loadSessionStore() (*sessionStore, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &sessionStore{
+		path:     filepath.Join(dir, "sessions.json"),
+		Sessions: make(map[string]storedSession),
+	}
+
+	b, err := ioutil.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, store); err != nil {
+		return nil, fmt.Errorf("could not read session store %s: %s", store.path, err)
+	}
+	if store.Sessions == nil {
+		store.Sessions = make(map[string]storedSession)
+	}
+
+	return store, nil
+}
+
+func sessionKey(host string) string {
+	return strings.TrimSuffix(host, "/")
+}
+
+// Get returns the stored session for the given host
+func (s *sessionStore) Get(host string) (storedSession, bool) {
+	session, ok := s.Sessions[sessionKey(host)]
+	return session, ok
+}
+
+// SaveSessionID stores a new session ID for the given host, keeping any remembered credential helper.
+func (s *sessionStore) SaveSessionID(host string, sessionID string) error {
+	session := s.Sessions[sessionKey(host)]
+	session.SessionID = sessionID
+	session.Created = time.Now()
+	s.Sessions[sessionKey(host)] = session
+	return s.save()
+}
+
+// SaveCredentialHelper remembers the credential helper command for the given host so expired sessions can be renewed.
+func (s *sessionStore) SaveCredentialHelper(host string, command string) error {
+	session := s.Sessions[sessionKey(host)]
+	session.CredentialHelper = command
+	s.Sessions[sessionKey(host)] = session
+	return s.save()
+}
+
+// save writes the store to a temporary file and renames it into place, so the file always ends up only readable by the
+// current user, even if an existing one was more permissive.
+func (s *sessionStore) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(filepath.Dir(s.path), "sessions-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), s.path)
+}
+
+// credentialHelper runs the given shell command to obtain credentials for the given host. The command gets the host in
+// HARBORMASTER_HOST and must print username=<username> and password=<password> lines, e.g.
+//
+//	echo "username=$USER"; echo "password=$(pass azkaban/prod)"
+func credentialHelper(command string, host string) azkaban.CredentialsProvider {
+	return func() (azkaban.Credentials, error) {
+		credentials := azkaban.Credentials{}
+
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=%s", HarbormasterHost, host))
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return credentials, fmt.Errorf("credential helper failed: %s", err)
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(out))
+		for scanner.Scan() {
+			kv := strings.SplitN(scanner.Text(), "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			switch strings.TrimSpace(kv[0]) {
+			case "username":
+				credentials.Username = kv[1]
+			case "password":
+				credentials.Password = kv[1]
+			}
+		}
+
+		if credentials.Username == "" || credentials.Password == "" {
+			return credentials, fmt.Errorf("credential helper did not print username and password")
+		}
+
+		return credentials, nil
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package cli
+
+import (
+	"os"
+)
+
+// terminalHeight returns how many lines the terminal f has, or 0 if that's unknown, which it always is on this
+// platform. watch falls back to printing changes line by line then.
+func terminalHeight(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/ilikeorangutans/harbormaster/projectdir"
+	"github.com/spf13/cobra"
+)
+
+func NewValidateCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate <dir|zip>",
+		Short: "check a project directory or zip for mistakes before uploading it",
+		Long: `Checks the .job and .properties files of Flow 1.0 projects, or the .flow files
+of Flow 2.0 projects, for missing dependencies, cycles, unknown job types,
+undefined ${param} references, and jobs not connected to any other job. Exits
+with 1 if there are errors:
+
+# harbormaster validate ./flows --job-type email,slack
+# harbormaster validate build/flows.zip`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			out, err := outputFromFlags(cmd)
+			if err != nil {
+				fatal(err)
+			}
+			jobTypes, _ := cmd.Flags().GetStringSlice("job-type")
+
+			project, problems, err := validateProject(args[0], jobTypes)
+			if err != nil {
+				fatal(err)
+			}
+
+			views := []problemView{}
+			for _, p := range problems {
+				views = append(views, newProblemView(p))
+			}
+			err = out.write(views, problems, func(w io.Writer) {
+				printProblems(w, project, problems)
+			})
+			if err != nil {
+				fatal(err)
+			}
+
+			if countProblems(problems, projectdir.Error) > 0 {
+				os.Exit(exitError)
+			}
+		},
+	}
+
+	addOutputFlags(cmd)
+	addJobTypeFlag(cmd)
+
+	return cmd
+}
+
+// addJobTypeFlag registers --job-type for job types of plugins installed in Azkaban besides the default ones
+func addJobTypeFlag(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("job-type", nil, "comma separated list of job types besides the default ones")
+}
+
+// validateProject loads and validates the project in the given directory or zip.
+func validateProject(path string, jobTypes []string) (*projectdir.Project, []projectdir.Problem, error) {
+	dir := path
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		if dir, err = unzipProject(path); err != nil {
+			return nil, nil, err
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	project, err := projectdir.Load(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	problems := projectdir.Validate(project, append(jobTypes, projectdir.DefaultJobTypes...))
+	project.Dir = path
+	return project, problems, nil
+}
+
+// unzipProject extracts the project zip at path to a new temporary directory, which the caller has to remove.
+func unzipProject(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := ioutil.TempDir("", "harbormaster")
+	if err != nil {
+		return "", err
+	}
+	if err := projectdir.Unzip(f, info.Size(), dir); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("could not read %s: %s", path, err)
+	}
+	return dir, nil
+}
+
+func printProblems(w io.Writer, project *projectdir.Project, problems []projectdir.Problem) {
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	fmt.Fprintf(
+		w,
+		"Flow %s project with %d flows: %d errors, %d warnings\n",
+		project.FlowVersion,
+		len(project.Flows),
+		countProblems(problems, projectdir.Error),
+		countProblems(problems, projectdir.Warning),
+	)
+}
+
+func countProblems(problems []projectdir.Problem, severity projectdir.Severity) int {
+	count := 0
+	for _, p := range problems {
+		if p.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
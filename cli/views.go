@@ -0,0 +1,205 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/projectdir"
+)
+
+// The view structs below are the schemas of harbormaster's machine readable output, documented in the README. Scripts
+// depend on them: add fields, but don't rename or remove them.
+
+type projectView struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+func newProjectView(p azkaban.Project) projectView {
+	return projectView{ID: p.ID, Name: p.Name}
+}
+
+type flowView struct {
+	Project string `json:"project"`
+	FlowID  string `json:"flowId"`
+}
+
+type executionView struct {
+	ExecutionID int64          `json:"executionId"`
+	Project     string         `json:"project"`
+	FlowID      string         `json:"flowId"`
+	Status      azkaban.Status `json:"status"`
+	SubmitTime  *time.Time     `json:"submitTime"`
+	StartTime   *time.Time     `json:"startTime"`
+	// EndTime is null while the execution is running
+	EndTime         *time.Time `json:"endTime"`
+	DurationSeconds int64      `json:"durationSeconds"`
+}
+
+func newExecutionView(project string, flowID string, e azkaban.Execution) executionView {
+	return executionView{
+		ExecutionID:     e.ID,
+		Project:         project,
+		FlowID:          flowID,
+		Status:          e.Status,
+		SubmitTime:      optionalTime(e.SubmitTime.Time()),
+		StartTime:       optionalTime(e.StartTime.Time()),
+		EndTime:         optionalTime(e.EndTime.Time()),
+		DurationSeconds: int64(e.Duration().Seconds()),
+	}
+}
+
+func newExecutionViews(project string, flowID string, executions azkaban.Executions) []executionView {
+	views := []executionView{}
+	for _, e := range executions {
+		views = append(views, newExecutionView(project, flowID, e))
+	}
+	return views
+}
+
+// flowHealthView summarizes the health of a flow from its most recent executions
+type flowHealthView struct {
+	Project     string         `json:"project"`
+	FlowID      string         `json:"flowId"`
+	Health      azkaban.Health `json:"health"`
+	Failures    int            `json:"failures"`
+	Successes   int            `json:"successes"`
+	Running     int            `json:"running"`
+	Total       int            `json:"total"`
+	LastSuccess *time.Time     `json:"lastSuccess"`
+	// Executions are the checked executions, most recent first
+	Executions []executionView `json:"executions"`
+}
+
+func newFlowHealthView(project string, flowID string, executions azkaban.Executions) flowHealthView {
+	histogram := executions.Histogram()
+	return flowHealthView{
+		Project:     project,
+		FlowID:      flowID,
+		Health:      executions.Health(),
+		Failures:    histogram.Failures,
+		Successes:   histogram.Successes,
+		Running:     histogram.Running,
+		Total:       histogram.Total,
+		LastSuccess: histogram.LastSuccess,
+		Executions:  newExecutionViews(project, flowID, executions),
+	}
+}
+
+type scheduleView struct {
+	ID             string     `json:"id"`
+	SubmitUser     string     `json:"submitUser"`
+	CronExpression string     `json:"cronExpression,omitempty"`
+	Period         string     `json:"period,omitempty"`
+	NextExecution  *time.Time `json:"nextExecution"`
+	// Upcoming are the next executions computed locally from cron expression or period
+	Upcoming []time.Time `json:"upcoming"`
+}
+
+// newScheduleView returns nil if the flow is not scheduled
+func newScheduleView(schedule azkaban.FlowSchedule, upcoming int) *scheduleView {
+	if !schedule.IsScheduled() {
+		return nil
+	}
+	view := &scheduleView{
+		ID:            schedule.ID,
+		SubmitUser:    schedule.SubmitUser,
+		NextExecution: optionalTime(schedule.NextExecTime.Time()),
+		Upcoming:      []time.Time{},
+	}
+	if schedule.CronExpression.IsZero() {
+		view.Period = schedule.Period.String()
+	} else {
+		view.CronExpression = schedule.CronExpression.String()
+	}
+	view.Upcoming = append(view.Upcoming, schedule.NextExecutions(upcoming)...)
+	return view
+}
+
+// flowStatusView is the result of check flow
+type flowStatusView struct {
+	flowHealthView
+	Schedule *scheduleView `json:"schedule"`
+	// FailedJob is the failed job of the most recent execution if the flow is critical
+	FailedJob string `json:"failedJob,omitempty"`
+}
+
+type averageExecutionTimeView struct {
+	FlowID       string `json:"flowId"`
+	SuccessCount int    `json:"successCount"`
+	FailureCount int    `json:"failureCount"`
+	// AverageSeconds is the average duration of successful executions
+	AverageSeconds int64 `json:"averageSeconds"`
+}
+
+// problemView is a problem found by validate
+type problemView struct {
+	Severity projectdir.Severity `json:"severity"`
+	File     string              `json:"file"`
+	Flow     string              `json:"flow,omitempty"`
+	Job      string              `json:"job,omitempty"`
+	Message  string              `json:"message"`
+}
+
+func newProblemView(p projectdir.Problem) problemView {
+	return problemView{Severity: p.Severity, File: p.File, Flow: p.Flow, Job: p.Job, Message: p.Message}
+}
+
+// changeView is a difference found by project diff
+type changeView struct {
+	Change   projectdir.ChangeKind `json:"change"`
+	Flow     string                `json:"flow"`
+	Job      string                `json:"job,omitempty"`
+	Property string                `json:"property,omitempty"`
+	Deployed string                `json:"deployed,omitempty"`
+	Local    string                `json:"local,omitempty"`
+}
+
+func newChangeView(c projectdir.Change) changeView {
+	return changeView{Change: c.Kind, Flow: c.Flow, Job: c.Job, Property: c.Property, Deployed: c.Deployed, Local: c.Local}
+}
+
+// projectVersionView is an uploaded version listed by project versions
+type projectVersionView struct {
+	Project    string    `json:"project"`
+	Version    int       `json:"version"`
+	UploadUser string    `json:"uploadUser"`
+	UploadTime time.Time `json:"uploadTime"`
+	Message    string    `json:"message"`
+}
+
+func newProjectVersionView(project string, v azkaban.ProjectVersion) projectVersionView {
+	return projectVersionView{Project: project, Version: v.Version, UploadUser: v.UploadUser, UploadTime: v.UploadTime, Message: v.Message}
+}
+
+// jobAnalysisView is the timing of a job analyzed by analyze
+type jobAnalysisView struct {
+	Job    string         `json:"job"`
+	Status azkaban.Status `json:"status"`
+	// StartSeconds is when the job started relative to the start of the execution
+	StartSeconds    int64 `json:"startSeconds"`
+	DurationSeconds int64 `json:"durationSeconds"`
+	WaitSeconds     int64 `json:"waitSeconds"`
+	SlackSeconds    int64 `json:"slackSeconds"`
+	Critical        bool  `json:"critical"`
+}
+
+func newJobAnalysisView(j azkaban.JobAnalysis) jobAnalysisView {
+	return jobAnalysisView{
+		Job:             j.Job.QualifiedID(),
+		Status:          j.Job.Status,
+		StartSeconds:    int64(j.Start.Seconds()),
+		DurationSeconds: int64(j.Duration.Seconds()),
+		WaitSeconds:     int64(j.Wait.Seconds()),
+		SlackSeconds:    int64(j.Slack.Seconds()),
+		Critical:        j.Critical,
+	}
+}
+
+// optionalTime returns nil for unset times, which Azkaban reports as -1
+func optionalTime(t time.Time) *time.Time {
+	if t.Unix() <= 0 {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/format"
+	"github.com/spf13/cobra"
+)
+
+func NewWaitCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait <execid|execution url>",
+		Short: "wait for an execution to finish and exit with 0 only if it succeeded",
+		Long: `Waits until an execution finishes, e.g. to gate deploys in CI on the outcome
+of a flow. Exits with 0 if the execution succeeded, 7 if it failed, 8 if it was
+killed, and 9 if it didn't finish within --max-wait:
+
+# harbormaster wait 12345 --max-wait 2h --log-tail 50 && deploy.sh`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			executionID, err := parseExecutionID(args[0])
+			if err != nil {
+				fatal(err)
+			}
+			maxWait, _ := cmd.Flags().GetDuration("max-wait")
+			interval, _ := cmd.Flags().GetDuration("interval")
+			logTail, _ := cmd.Flags().GetInt("log-tail")
+
+			client := context.Client()
+			status, err := waitForExecution(client, executionID, maxWait, interval)
+			if err == errWaitTimeout {
+				log.Printf("execution %d did not finish within %s", executionID, maxWait)
+				os.Exit(exitWaitTimeout)
+			}
+			if err != nil {
+				fatal(err)
+			}
+
+			fmt.Printf(
+				"execution %d of %s %s %s after %s\n",
+				status.ExecutionID,
+				status.Project,
+				status.FlowID,
+				status.Status,
+				format.DurationHumanReadable(elapsed(status.StartTime, status.EndTime, time.Now())),
+			)
+			if status.Status.IsFailure() && logTail > 0 {
+				for _, job := range status.FailedJobs() {
+					if err := printLogTail(client, executionID, job.QualifiedID(), logTail); err != nil {
+						log.Printf("could not fetch log of job %s: %s", job.QualifiedID(), err)
+					}
+				}
+			}
+
+			if code := statusExitCode(status.Status); code != 0 {
+				os.Exit(code)
+			}
+		},
+	}
+
+	cmd.Flags().Duration("max-wait", 0, "how long to wait for the execution to finish, e.g. 2h, 0 to wait forever")
+	cmd.Flags().Duration("interval", 10*time.Second, "how often to ask Azkaban whether the execution finished")
+	cmd.Flags().Int("log-tail", 0, "print the last lines of the logs of failed jobs if the execution failed")
+
+	return cmd
+}
+
+var errWaitTimeout = errors.New("timed out waiting for the execution to finish")
+
+// waitForExecution waits until the execution finished or the timeout passed, in which case it returns the last
+// status and errWaitTimeout. A timeout of 0 waits forever.
+func waitForExecution(client *azkaban.Client, executionID int64, timeout time.Duration, interval time.Duration) (azkaban.FlowExecutionStatus, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var last azkaban.FlowExecutionStatus
+	status, err := watchExecution(ctx, client, executionID, interval, func(s azkaban.FlowExecutionStatus) { last = s })
+	if ctx.Err() == context.DeadlineExceeded {
+		return last, errWaitTimeout
+	}
+	return status, err
+}
+
+// printLogTail prints the last lines of the log of the given job.
+func printLogTail(client *azkaban.Client, executionID int64, jobID string, lines int) error {
+	var buf bytes.Buffer
+	if _, err := client.FetchLogsUntilEnd(executionID, jobID, 0, &buf); err != nil {
+		return err
+	}
+
+	fmt.Printf("==> last %d lines of the log of job %s <==\n", lines, jobID)
+	fmt.Print(lastLines(buf.String(), lines))
+	return nil
+}
+
+// lastLines returns the last n lines of the text.
+func lastLines(text string, n int) string {
+	all := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	if len(all) > n {
+		all = all[len(all)-n:]
+	}
+	result := strings.Join(all, "")
+	if result != "" && !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+	return result
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/azkabantest"
+)
+
+func TestWaitForExecutionTimeout(t *testing.T) {
+	s := azkabantest.NewServer()
+	defer s.Close()
+	s.AddProject("example")
+	s.AddFlow("example", "daily", azkaban.FlowJob{ID: "extract", Type: "command"})
+	e := s.AddExecution("example", "daily", "RUNNING", time.Now())
+
+	status, err := waitForExecution(s.Client(), e.ID, 30*time.Millisecond, 5*time.Millisecond)
+	if err != errWaitTimeout || status.Status != "RUNNING" {
+		t.Errorf("expected timeout with the last status, got %v, %v", status.Status, err)
+	}
+}
+
+func TestLastLines(t *testing.T) {
+	tests := []struct {
+		text     string
+		n        int
+		expected string
+	}{
+		{"a\nb\nc\n", 2, "b\nc\n"},
+		{"a\nb\nc", 2, "b\nc\n"},
+		{"a\n", 5, "a\n"},
+		{"", 5, ""},
+	}
+	for _, test := range tests {
+		if result := lastLines(test.text, test.n); result != test.expected {
+			t.Errorf("lastLines(%q, %d): expected %q, got %q", test.text, test.n, test.expected, result)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"github.com/ilikeorangutans/harbormaster/format"
+	"github.com/spf13/cobra"
+)
+
+func NewWatchCmd(context Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watch <execid|execution url>",
+		Short: "watch the jobs of an execution until it finishes",
+		Long: `Shows the jobs of an execution with their status, elapsed time, and attempts,
+updated until the execution finishes. On a terminal the tree is redrawn in place
+on every poll, cut off at the bottom of the terminal, otherwise every change is
+printed on its own line. Exits with 0 if the execution
+succeeded, 7 if it failed, 8 if it was killed, and 130 if stopped with Ctrl-C:
+
+# harbormaster watch 12345
+# harbormaster watch '<azkaban url>/executor?execid=12345' && deploy.sh`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			executionID, err := parseExecutionID(args[0])
+			if err != nil {
+				fatal(err)
+			}
+			interval, _ := cmd.Flags().GetDuration("interval")
+
+			interrupted, stop := interruptContext()
+			defer stop()
+
+			var height func() int
+			if isTerminal(os.Stdout) && terminalHeight(os.Stdout) > 0 {
+				height = func() int { return terminalHeight(os.Stdout) }
+			}
+			renderer := newWatchRenderer(os.Stdout, height)
+			status, err := watchExecution(interrupted, context.Client(), executionID, interval, renderer.render)
+			if interrupted.Err() != nil {
+				// Don't let e.g. `watch && deploy.sh` carry on after the user stopped watching
+				stop()
+				os.Exit(exitInterrupted)
+			}
+			if err != nil {
+				fatal(err)
+			}
+			renderer.finish(status)
+
+			if code := statusExitCode(status.Status); code != 0 {
+				os.Exit(code)
+			}
+		},
+	}
+
+	cmd.Flags().Duration("interval", 2*time.Second, "how often to ask Azkaban for changes")
+
+	return cmd
+}
+
+// watchExecution polls the execution until it finishes and calls onPoll with its status first and after every poll,
+// whether anything changed or not. It returns the final status.
+func watchExecution(ctx context.Context, client *azkaban.Client, executionID int64, interval time.Duration, onPoll func(azkaban.FlowExecutionStatus)) (azkaban.FlowExecutionStatus, error) {
+	status, err := client.FlowExecutionStatusContext(ctx, executionID)
+	if err != nil {
+		return status, err
+	}
+	onPoll(status)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for !status.Status.IsFinished() {
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+
+		update, err := client.FlowExecutionUpdateContext(ctx, executionID, status.UpdateTime)
+		if err != nil {
+			return status, err
+		}
+		status.Apply(update)
+		onPoll(status)
+	}
+
+	return status, nil
+}
+
+// watchRenderer shows the jobs of a watched execution. On terminals it redraws the whole tree every time so elapsed
+// times keep counting, otherwise it prints the tree once and then a line for every job that changed.
+type watchRenderer struct {
+	w io.Writer
+	// height returns how many lines the terminal has, nil if w isn't a terminal
+	height func() int
+	// lines is the height of the tree last drawn on the terminal
+	lines int
+	// seen are the job statuses and attempts last printed
+	seen map[string]azkaban.JobStatus
+}
+
+func newWatchRenderer(w io.Writer, height func() int) *watchRenderer {
+	return &watchRenderer{w: w, height: height}
+}
+
+func (r *watchRenderer) render(status azkaban.FlowExecutionStatus) {
+	now := time.Now()
+	if r.height != nil {
+		var buf bytes.Buffer
+		printExecutionTree(&buf, status, now)
+		// Moving the cursor up only works within the screen, so the tree and the line below it have to fit on it
+		tree := clipLines(buf.String(), r.height()-1)
+		if r.lines > 0 {
+			// Move the cursor to the start of the previous tree and clear it
+			fmt.Fprintf(r.w, "\033[%dA\033[J", r.lines)
+		}
+		r.lines = strings.Count(tree, "\n")
+		io.WriteString(r.w, tree)
+		return
+	}
+
+	if r.seen == nil {
+		printExecutionTree(r.w, status, now)
+		r.seen = make(map[string]azkaban.JobStatus)
+		status.Walk(func(n azkaban.JobStatus, depth int) { r.seen[n.QualifiedID()] = n })
+		return
+	}
+	status.Walk(func(n azkaban.JobStatus, depth int) {
+		if last := r.seen[n.QualifiedID()]; last.Status == n.Status && last.Attempt == n.Attempt {
+			return
+		}
+		r.seen[n.QualifiedID()] = n
+		fmt.Fprintf(r.w, "%s %s %s%s\n", now.Format("15:04:05"), n.QualifiedID(), n.Status.Colored(), attemptSuffix(n))
+	})
+}
+
+// finish prints the outcome of the execution below the change lines, the terminal already shows it in the tree.
+func (r *watchRenderer) finish(status azkaban.FlowExecutionStatus) {
+	if r.height != nil {
+		return
+	}
+	fmt.Fprintf(
+		r.w,
+		"execution %d of %s %s %s after %s\n",
+		status.ExecutionID,
+		status.Project,
+		status.FlowID,
+		status.Status,
+		format.DurationHumanReadable(elapsed(status.StartTime, status.EndTime, time.Now())),
+	)
+}
+
+// clipLines cuts text down to at most max lines, replacing the ones cut off with a line telling how many there were.
+func clipLines(text string, max int) string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) <= max {
+		return text
+	}
+	if max < 2 {
+		max = 2
+	}
+	return strings.Join(lines[:max-1], "") + fmt.Sprintf("... %d more lines\n", len(lines)-max+1)
+}
+
+// printExecutionTree prints the execution and its jobs, see printJobTree.
+func printExecutionTree(writer io.Writer, status azkaban.FlowExecutionStatus, now time.Time) {
+	fmt.Fprintf(
+		writer,
+		"Execution %d of %s %s: %s %s\n",
+		status.ExecutionID,
+		status.Project,
+		status.FlowID,
+		status.Status.Colored(),
+		format.DurationHumanReadable(elapsed(status.StartTime, status.EndTime, now)),
+	)
+
+	w := new(tabwriter.Writer)
+	w.Init(writer, 4, 4, 2, ' ', 0)
+	printJobTree(w, status.Nodes, "", now)
+	w.Flush()
+}
+
+// printJobTree prints the jobs, each indented below the jobs it depends on, and the jobs of embedded flows indented
+// below the flow.
+func printJobTree(w io.Writer, jobs []azkaban.JobStatus, indent string, now time.Time) {
+	levels := jobLevels(jobs)
+	for _, n := range jobs {
+		prefix := indent
+		if level := levels[n.ID]; level > 0 {
+			prefix += strings.Repeat("   ", level-1) + "└─ "
+		}
+		fmt.Fprintf(
+			w,
+			"%s%s\t%s\t%s\t%s\n",
+			prefix,
+			n.ID,
+			n.Status.Colored(),
+			format.DurationHumanReadable(elapsed(n.StartTime, n.EndTime, now)),
+			strings.TrimSpace(attemptSuffix(n)),
+		)
+		if len(n.Nodes) > 0 {
+			printJobTree(w, n.Nodes, strings.Repeat(" ", utf8.RuneCountInString(prefix)+3), now)
+		}
+	}
+}
+
+// jobLevels returns how deep each job is nested in the flow: 0 for jobs without dependencies, otherwise one more than
+// the deepest job it depends on.
+func jobLevels(jobs []azkaban.JobStatus) map[string]int {
+	byID := make(map[string]azkaban.JobStatus)
+	for _, j := range jobs {
+		byID[j.ID] = j
+	}
+
+	levels := make(map[string]int)
+	var level func(id string, visiting map[string]bool) int
+	level = func(id string, visiting map[string]bool) int {
+		if l, ok := levels[id]; ok {
+			return l
+		}
+		// Guards against cycles, which Azkaban doesn't allow anyway
+		if visiting[id] {
+			return 0
+		}
+		visiting[id] = true
+		l := 0
+		for _, dep := range byID[id].In {
+			if _, ok := byID[dep]; ok {
+				if d := level(dep, visiting) + 1; d > l {
+					l = d
+				}
+			}
+		}
+		levels[id] = l
+		return l
+	}
+	for _, j := range jobs {
+		level(j.ID, make(map[string]bool))
+	}
+	return levels
+}
+
+func attemptSuffix(j azkaban.JobStatus) string {
+	if j.Attempt == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (attempt %d)", j.Attempt+1)
+}
+
+// elapsed returns how long something ran, until now if it hasn't ended yet, or 0 if it hasn't started.
+func elapsed(start azkaban.AzkabanTimestamp, end azkaban.AzkabanTimestamp, now time.Time) time.Duration {
+	if start.Time().Unix() <= 0 {
+		return 0
+	}
+	if end.Time().Unix() <= 0 {
+		return now.Sub(start.Time())
+	}
+	return end.Time().Sub(start.Time())
+}
+
+// isTerminal returns true if the file is a terminal rather than e.g. a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
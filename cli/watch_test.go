@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+func TestWatchRenderer(t *testing.T) {
+	start := azkaban.AzkabanTimestamp(time.Now().Add(-time.Minute))
+	status := azkaban.FlowExecutionStatus{ExecutionID: 1, Project: "example", FlowID: "daily", Status: "RUNNING", StartTime: start}
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		status.Nodes = append(status.Nodes, azkaban.JobStatus{ID: id, Status: "RUNNING", StartTime: start})
+	}
+
+	// On a terminal the tree is redrawn even if nothing changed, and cut off to fit on the screen
+	var buf bytes.Buffer
+	r := newWatchRenderer(&buf, func() int { return 5 })
+	r.render(status)
+	r.render(status)
+	out := buf.String()
+	if !strings.Contains(out, "\033[4A\033[J") || strings.Count(out, "Execution 1 of example daily") != 2 {
+		t.Errorf("expected the tree to be redrawn, got %q", out)
+	}
+	if !strings.Contains(out, "... 3 more lines\n") || strings.Contains(out, "\ne ") {
+		t.Errorf("expected the tree to be cut off at 4 lines, got %q", out)
+	}
+
+	// Otherwise only changes are printed
+	buf.Reset()
+	r = newWatchRenderer(&buf, nil)
+	r.render(status)
+	buf.Reset()
+	r.render(status)
+	status.Nodes[0].Status = "SUCCEEDED"
+	r.render(status)
+	if out := buf.String(); !strings.HasSuffix(out, " a SUCCEEDED\n") || strings.Count(out, "\n") != 1 {
+		t.Errorf("expected only the change of a, got %q", out)
+	}
+}
+
+func TestClipLines(t *testing.T) {
+	tests := []struct {
+		text     string
+		max      int
+		expected string
+	}{
+		{"a\nb\nc\n", 3, "a\nb\nc\n"},
+		{"a\nb\nc\nd\n", 3, "a\nb\n... 2 more lines\n"},
+		{"a\nb\nc\n", 0, "a\n... 2 more lines\n"},
+		{"", 3, ""},
+	}
+	for _, test := range tests {
+		if result := clipLines(test.text, test.max); result != test.expected {
+			t.Errorf("clipLines(%q, %d): expected %q, got %q", test.text, test.max, test.expected, result)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 func main() {
 	if err := cli.NewCLI().Execute(); err != nil {
 		fmt.Println(err)
-		os.Exit(1)
+		// Commands exit themselves, cobra only returns errors for invalid arguments and flags
+		os.Exit(2)
 	}
 }
@@ -0,0 +1,185 @@
+package projectdir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+// DeployedFlow is a flow as it is deployed in Azkaban.
+type DeployedFlow struct {
+	ID   string
+	Jobs []azkaban.FlowJob
+	// Properties are the properties of the flow's jobs by job ID
+	Properties map[string]map[string]string
+}
+
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change is a difference between a local project and the deployed one. Added and removed are meant as what uploading
+// the local project would do, e.g. a flow that only exists locally is added.
+type Change struct {
+	Kind ChangeKind
+	Flow string
+	// Job is empty if the whole flow was added or removed
+	Job string
+	// Property is the name of the changed property, "type" and "dependencies" for changed types and dependencies, or
+	// empty if the whole job was added or removed
+	Property string
+	Deployed string
+	Local    string
+}
+
+func (c Change) String() string {
+	switch {
+	case c.Job == "" && c.Kind == Added:
+		return fmt.Sprintf("+ flow %s", c.Flow)
+	case c.Job == "":
+		return fmt.Sprintf("- flow %s", c.Flow)
+	case c.Property == "" && c.Kind == Added:
+		return fmt.Sprintf("+ job %s/%s", c.Flow, c.Job)
+	case c.Property == "":
+		return fmt.Sprintf("- job %s/%s", c.Flow, c.Job)
+	case c.Kind == Added:
+		return fmt.Sprintf("+ %s/%s %s: %s", c.Flow, c.Job, c.Property, c.Local)
+	case c.Kind == Removed:
+		return fmt.Sprintf("- %s/%s %s: %s", c.Flow, c.Job, c.Property, c.Deployed)
+	}
+	return fmt.Sprintf("~ %s/%s %s: %s -> %s", c.Flow, c.Job, c.Property, c.Deployed, c.Local)
+}
+
+// Diff compares the flows, job dependencies, and job properties of the local project with the deployed flows. Changes
+// are sorted by flow and job.
+func Diff(local *Project, deployed []DeployedFlow) []Change {
+	var localIDs, deployedIDs []string
+	localFlows := make(map[string]*Flow)
+	for _, f := range local.Flows {
+		localFlows[f.ID] = f
+		localIDs = append(localIDs, f.ID)
+	}
+	deployedFlows := make(map[string]DeployedFlow)
+	for _, f := range deployed {
+		deployedFlows[f.ID] = f
+		deployedIDs = append(deployedIDs, f.ID)
+	}
+
+	var changes []Change
+	for _, id := range union(localIDs, deployedIDs) {
+		l, inLocal := localFlows[id]
+		d, inDeployed := deployedFlows[id]
+		switch {
+		case !inDeployed:
+			changes = append(changes, Change{Kind: Added, Flow: id})
+		case !inLocal:
+			changes = append(changes, Change{Kind: Removed, Flow: id})
+		default:
+			changes = append(changes, diffFlow(l, d)...)
+		}
+	}
+
+	return changes
+}
+
+func diffFlow(local *Flow, deployed DeployedFlow) []Change {
+	var localIDs, deployedIDs []string
+	localJobs := make(map[string]*Job)
+	for _, j := range local.Jobs {
+		localJobs[j.ID] = j
+		localIDs = append(localIDs, j.ID)
+	}
+	deployedJobs := make(map[string]azkaban.FlowJob)
+	for _, j := range deployed.Jobs {
+		deployedJobs[j.ID] = j
+		deployedIDs = append(deployedIDs, j.ID)
+	}
+
+	var changes []Change
+	for _, id := range union(localIDs, deployedIDs) {
+		l, inLocal := localJobs[id]
+		d, inDeployed := deployedJobs[id]
+		switch {
+		case !inDeployed:
+			changes = append(changes, Change{Kind: Added, Flow: local.ID, Job: id})
+			continue
+		case !inLocal:
+			changes = append(changes, Change{Kind: Removed, Flow: local.ID, Job: id})
+			continue
+		}
+
+		change := Change{Kind: Changed, Flow: local.ID, Job: id}
+		if l.Type != d.Type {
+			change.Property, change.Deployed, change.Local = "type", d.Type, l.Type
+			changes = append(changes, change)
+		}
+		if localDeps, deployedDeps := sortedList(l.In), sortedList(d.In); localDeps != deployedDeps {
+			change.Property, change.Deployed, change.Local = "dependencies", deployedDeps, localDeps
+			changes = append(changes, change)
+		}
+		changes = append(changes, diffProperties(local.ID, id, l.Properties, deployed.Properties[id])...)
+	}
+
+	return changes
+}
+
+func diffProperties(flow string, job string, local map[string]string, deployed map[string]string) []Change {
+	var changes []Change
+	for _, key := range union(keys(local), keys(deployed)) {
+		// Compared on their own above
+		if key == "type" || key == "dependencies" {
+			continue
+		}
+		l, inLocal := local[key]
+		d, inDeployed := deployed[key]
+		change := Change{Flow: flow, Job: job, Property: key, Deployed: d, Local: l}
+		switch {
+		case !inDeployed:
+			change.Kind = Added
+		case !inLocal:
+			change.Kind = Removed
+		case l != d:
+			change.Kind = Changed
+		default:
+			continue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// union returns the sorted values occurring in any of the given lists.
+func union(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, list := range lists {
+		for _, v := range list {
+			if !seen[v] {
+				seen[v] = true
+				result = append(result, v)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func keys(m map[string]string) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+	return result
+}
+
+func sortedList(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
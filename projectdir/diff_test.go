@@ -0,0 +1,58 @@
+package projectdir
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+// deploy returns the flows of the project as Azkaban reports them after uploading it.
+func deploy(project *Project) []DeployedFlow {
+	var flows []DeployedFlow
+	for _, f := range project.Flows {
+		deployed := DeployedFlow{ID: f.ID, Jobs: f.FlowJobs(), Properties: make(map[string]map[string]string)}
+		for _, j := range f.Jobs {
+			deployed.Properties[j.ID] = merge(j.Properties)
+		}
+		flows = append(flows, deployed)
+	}
+	return flows
+}
+
+func TestDiff(t *testing.T) {
+	project, err := Load("testdata/flow1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes := Diff(project, deploy(project)); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+
+	// The deployed project is older: no cleanup flow, report has an old flow of its own
+	deployed := deploy(project)[1:]
+	deployed = append(deployed, DeployedFlow{ID: "old", Jobs: []azkaban.FlowJob{{ID: "old", Type: "command"}}})
+	report := &deployed[2]
+	report.Jobs = append([]azkaban.FlowJob{}, report.Jobs[1:]...)
+	report.Jobs[1].In = []string{"extract", "transform"}
+	report.Jobs = append(report.Jobs, azkaban.FlowJob{ID: "notify", Type: "email", In: []string{"report"}})
+	report.Properties["transform"]["command"] = "transform.sh"
+	report.Properties["load"]["retries"] = "3"
+
+	var lines []string
+	for _, c := range Diff(project, deployed) {
+		lines = append(lines, c.String())
+	}
+	expected := []string{
+		"+ flow cleanup",
+		"- flow old",
+		"+ job report/extract",
+		"~ report/load dependencies: extract, transform -> transform",
+		"- report/load retries: 3",
+		"- job report/notify",
+		"~ report/transform command: transform.sh -> transform.sh --input ${base.dir}",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("expected changes\n%v\ngot\n%v", expected, lines)
+	}
+}
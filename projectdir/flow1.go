@@ -0,0 +1,113 @@
+package projectdir
+
+import (
+	"path"
+	"sort"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+// loadFlow1 reads the .job and .properties files of a Flow 1.0 project. Jobs inherit the properties of .properties
+// files in their directory and its parents. Every job no other job depends on is the last job of a flow named after
+// it.
+func loadFlow1(project *Project, files []string) error {
+	dirProperties := make(map[string]map[string]string)
+	for _, f := range files {
+		if ext(f) != ".properties" {
+			continue
+		}
+		content, err := readFile(project.Dir, f)
+		if err != nil {
+			return err
+		}
+		dir := dirOf(f)
+		dirProperties[dir] = merge(dirProperties[dir], parseProperties(content))
+	}
+
+	for _, f := range files {
+		switch ext(f) {
+		case ".job":
+			content, err := readFile(project.Dir, f)
+			if err != nil {
+				return err
+			}
+			props := parseProperties(content)
+			job := &Job{
+				FlowJob: azkaban.FlowJob{
+					ID:   baseName(f),
+					Type: props["type"],
+					In:   splitList(props["dependencies"]),
+				},
+				File:       f,
+				Properties: props,
+			}
+			for _, dir := range ancestors(dirOf(f)) {
+				job.inherited = merge(job.inherited, dirProperties[dir])
+			}
+			project.Jobs = append(project.Jobs, job)
+		case ".flow":
+			project.Ignored = append(project.Ignored, f)
+		}
+	}
+	sort.SliceStable(project.Jobs, func(i, j int) bool { return project.Jobs[i].ID < project.Jobs[j].ID })
+
+	project.Flows = flow1Flows(project.Jobs)
+	return nil
+}
+
+// flow1Flows derives the flows from the dependencies between jobs. Jobs of a flow are ordered so that dependencies come
+// before the jobs depending on them.
+func flow1Flows(jobs []*Job) []*Flow {
+	byID := make(map[string]*Job)
+	dependedOn := make(map[string]bool)
+	for _, j := range jobs {
+		if _, ok := byID[j.ID]; !ok {
+			byID[j.ID] = j
+		}
+		for _, dep := range j.In {
+			dependedOn[dep] = true
+		}
+	}
+
+	var flows []*Flow
+	for _, last := range jobs {
+		if dependedOn[last.ID] || byID[last.ID] != last {
+			continue
+		}
+		flow := &Flow{ID: last.ID}
+		visited := make(map[string]bool)
+		var visit func(id string)
+		visit = func(id string) {
+			j, ok := byID[id]
+			if visited[id] || !ok {
+				return
+			}
+			visited[id] = true
+			for _, dep := range j.In {
+				visit(dep)
+			}
+			flow.Jobs = append(flow.Jobs, j)
+		}
+		visit(last.ID)
+		flows = append(flows, flow)
+	}
+
+	return flows
+}
+
+// dirOf returns the directory of the given slash separated path, "" for the project directory.
+func dirOf(file string) string {
+	dir := path.Dir(file)
+	if dir == "." {
+		return ""
+	}
+	return dir
+}
+
+// ancestors returns the given directory and all its parents, outermost first.
+func ancestors(dir string) []string {
+	if dir == "" {
+		return []string{""}
+	}
+	return append(ancestors(dirOf(dir)), dir)
+}
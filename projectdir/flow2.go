@@ -0,0 +1,90 @@
+package projectdir
+
+import (
+	"fmt"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+	"gopkg.in/yaml.v2"
+)
+
+type flowFile struct {
+	Config map[string]interface{} `yaml:"config"`
+	Nodes  []flowNode             `yaml:"nodes"`
+}
+
+type flowNode struct {
+	Name      string                 `yaml:"name"`
+	Type      string                 `yaml:"type"`
+	DependsOn []string               `yaml:"dependsOn"`
+	Config    map[string]interface{} `yaml:"config"`
+	// Nodes are the jobs of an embedded flow
+	Nodes []flowNode `yaml:"nodes"`
+}
+
+// loadFlow2 reads the .flow files of a Flow 2.0 project, each defines a flow named after the file. Jobs inherit the
+// config of their flow and of the embedded flows they are part of.
+func loadFlow2(project *Project, files []string) error {
+	for _, f := range files {
+		switch ext(f) {
+		case ".flow":
+			content, err := readFile(project.Dir, f)
+			if err != nil {
+				return err
+			}
+			var definition flowFile
+			if err := yaml.Unmarshal(content, &definition); err != nil {
+				return fmt.Errorf("%s: %s", f, err)
+			}
+
+			flow := &Flow{ID: baseName(f), File: f, Config: stringMap(definition.Config)}
+			flow.Jobs = flow2Jobs(definition.Nodes, f, flow.Config)
+			project.Flows = append(project.Flows, flow)
+		case ".job":
+			project.Ignored = append(project.Ignored, f)
+		}
+	}
+
+	return nil
+}
+
+func flow2Jobs(nodes []flowNode, file string, inherited map[string]string) []*Job {
+	var jobs []*Job
+	for _, n := range nodes {
+		job := &Job{
+			FlowJob: azkaban.FlowJob{
+				ID:   n.Name,
+				Type: n.Type,
+				In:   n.DependsOn,
+			},
+			File:       file,
+			Properties: stringMap(n.Config),
+			inherited:  inherited,
+		}
+		if len(n.Nodes) > 0 {
+			job.Jobs = flow2Jobs(n.Nodes, file, merge(inherited, job.Properties))
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// readYAMLProperties reads a YAML file of key value pairs like .project files.
+func readYAMLProperties(dir string, file string) (map[string]string, error) {
+	content, err := readFile(dir, file)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &values); err != nil {
+		return nil, err
+	}
+	return stringMap(values), nil
+}
+
+func stringMap(values map[string]interface{}) map[string]string {
+	result := make(map[string]string)
+	for k, v := range values {
+		result[k] = fmt.Sprint(v)
+	}
+	return result
+}
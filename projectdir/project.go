@@ -0,0 +1,151 @@
+package projectdir
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+const (
+	// Flow1 projects define every job in a .job file, flows are derived from the dependencies between jobs
+	Flow1 = "1.0"
+	// Flow2 projects define every flow in a .flow YAML file next to a .project file
+	Flow2 = "2.0"
+)
+
+// Project is a project directory as Azkaban sees it after the upload.
+type Project struct {
+	Dir string
+	// FlowVersion is Flow1 or Flow2
+	FlowVersion string
+	Flows       []*Flow
+	// Jobs are all jobs of a Flow 1.0 project, a job can be part of several flows. Empty for Flow 2.0.
+	Jobs []*Job
+	// Ignored are files Azkaban ignores, relative to Dir, e.g. .job files in Flow 2.0 projects
+	Ignored []string
+}
+
+// Flow is a flow of a project.
+type Flow struct {
+	ID string
+	// File is the .flow file of a Flow 2.0 flow, relative to the project directory, empty for Flow 1.0
+	File string
+	// Config are the flow level properties of a Flow 2.0 flow
+	Config map[string]string
+	Jobs   []*Job
+}
+
+// Job returns the job with the given ID, or nil.
+func (f *Flow) Job(id string) *Job {
+	for _, j := range f.Jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// FlowJobs returns the dependency graph of the flow's jobs like Azkaban reports it for deployed flows.
+func (f *Flow) FlowJobs() []azkaban.FlowJob {
+	jobs := []azkaban.FlowJob{}
+	for _, j := range f.Jobs {
+		jobs = append(jobs, j.FlowJob)
+	}
+	return jobs
+}
+
+// Job is a job, or an embedded flow of a Flow 2.0 project.
+type Job struct {
+	azkaban.FlowJob
+	// File is the .job or .flow file defining the job, relative to the project directory
+	File string
+	// Properties are the job's own properties. For Flow 1.0 jobs these include type and dependencies, for Flow 2.0
+	// jobs they are the node's config.
+	Properties map[string]string
+	// Jobs are the jobs of an embedded Flow 2.0 flow
+	Jobs []*Job
+
+	// inherited are the properties the job inherits from .properties files or the configs of the flows it is part of
+	inherited map[string]string
+}
+
+// Load reads the project in the given directory. It only fails if files can't be read or parsed, use Validate to find
+// problems in the flows.
+func Load(dir string) (*Project, error) {
+	files, err := projectFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	project := &Project{Dir: dir, FlowVersion: Flow1}
+	for _, f := range files {
+		if f == ".project" {
+			props, err := readYAMLProperties(dir, f)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %s", f, err)
+			}
+			if props["azkaban-flow-version"] == Flow2 || props["azkaban-flow-version"] == "2" {
+				project.FlowVersion = Flow2
+			}
+		}
+	}
+
+	if project.FlowVersion == Flow2 {
+		err = loadFlow2(project, files)
+	} else {
+		err = loadFlow1(project, files)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(project.Flows, func(i, j int) bool { return project.Flows[i].ID < project.Flows[j].ID })
+	return project, nil
+}
+
+// projectFiles returns the paths of all files in dir relative to dir with forward slashes, skipping hidden files other
+// than .project.
+func projectFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(info.Name(), ".") && info.Name() != ".project" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	return files, err
+}
+
+func readFile(dir string, file string) ([]byte, error) {
+	return ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+}
+
+// ext returns the extension of the file, e.g. ".job"
+func ext(file string) string {
+	return filepath.Ext(file)
+}
+
+// baseName returns the file name without directory and extension, e.g. "load" for "daily/load.job"
+func baseName(file string) string {
+	base := filepath.Base(filepath.FromSlash(file))
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
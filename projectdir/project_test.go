@@ -0,0 +1,81 @@
+package projectdir
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ilikeorangutans/harbormaster/azkaban"
+)
+
+func TestParseProperties(t *testing.T) {
+	content := "# comment\n! also a comment\ntype=command\ncommand = echo \\\n  hello\nkey: value\nspaced value\n\nempty=\n"
+	expected := map[string]string{
+		"type":    "command",
+		"command": "echo hello",
+		"key":     "value",
+		"spaced":  "value",
+		"empty":   "",
+	}
+	if props := parseProperties([]byte(content)); !reflect.DeepEqual(props, expected) {
+		t.Errorf("expected %v, got %v", expected, props)
+	}
+}
+
+func TestLoadFlow1(t *testing.T) {
+	project, err := Load("testdata/flow1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.FlowVersion != Flow1 || len(project.Jobs) != 9 {
+		t.Fatalf("unexpected project %v", project)
+	}
+
+	var flows []string
+	for _, f := range project.Flows {
+		flows = append(flows, f.ID)
+	}
+	if expected := []string{"cleanup", "embed", "embed_missing", "report"}; !reflect.DeepEqual(flows, expected) {
+		t.Errorf("expected flows %v, got %v", expected, flows)
+	}
+
+	report := project.Flows[3]
+	expected := []azkaban.FlowJob{
+		{ID: "extract", Type: "command"},
+		{ID: "transform", Type: "command", In: []string{"extract"}},
+		{ID: "load", Type: "command", In: []string{"transform"}},
+		{ID: "report", Type: "email", In: []string{"load", "publish"}},
+	}
+	if jobs := report.FlowJobs(); !reflect.DeepEqual(jobs, expected) {
+		t.Errorf("expected jobs %v, got %v", expected, jobs)
+	}
+
+	transform := report.Job("transform")
+	if transform.File != "transform/transform.job" || transform.Properties["command"] != "transform.sh --input ${base.dir}" {
+		t.Errorf("unexpected job %v", transform)
+	}
+	if transform.inherited["env"] != "prod" || transform.inherited["threads"] != "4" {
+		t.Errorf("expected properties of parent directories to be inherited, got %v", transform.inherited)
+	}
+}
+
+func TestLoadFlow2(t *testing.T) {
+	project, err := Load("testdata/flow2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.FlowVersion != Flow2 || len(project.Flows) != 2 || !reflect.DeepEqual(project.Ignored, []string{"old.job"}) {
+		t.Fatalf("unexpected project %v", project)
+	}
+
+	daily := project.Flows[0]
+	if daily.ID != "daily" || daily.File != "daily.flow" || daily.Config["env"] != "prod" {
+		t.Errorf("unexpected flow %v", daily)
+	}
+	process := daily.Job("process")
+	if process.Type != "flow" || !reflect.DeepEqual(process.In, []string{"extract"}) || len(process.Jobs) != 2 {
+		t.Fatalf("unexpected embedded flow %v", process)
+	}
+	if check := process.Jobs[1]; !reflect.DeepEqual(check.In, []string{"transform", "missing"}) || check.inherited["threads"] != "4" {
+		t.Errorf("unexpected job %v", check)
+	}
+}
@@ -0,0 +1,93 @@
+package projectdir
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// parseProperties parses Java properties files as used by .job and .properties files: key=value, key: value, or
+// key value lines, # and ! comments, and values continued on the next line with a trailing backslash.
+func parseProperties(content []byte) map[string]string {
+	props := make(map[string]string)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	var line string
+	for scanner.Scan() {
+		text := strings.TrimLeft(scanner.Text(), " \t")
+		if line == "" && (text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "!")) {
+			continue
+		}
+		if strings.HasSuffix(text, `\`) && !strings.HasSuffix(text, `\\`) {
+			line += strings.TrimSuffix(text, `\`)
+			continue
+		}
+		line += text
+
+		key, value := splitProperty(line)
+		props[key] = value
+		line = ""
+	}
+	if line != "" {
+		key, value := splitProperty(line)
+		props[key] = value
+	}
+
+	return props
+}
+
+func splitProperty(line string) (string, string) {
+	i := strings.IndexAny(line, "=: \t")
+	if i < 0 {
+		return line, ""
+	}
+	key := line[:i]
+	value := strings.TrimLeft(line[i:], " \t")
+	if strings.HasPrefix(value, "=") || strings.HasPrefix(value, ":") {
+		value = value[1:]
+	}
+	return key, strings.TrimSpace(value)
+}
+
+// splitList splits comma separated property values like dependencies.
+func splitList(value string) []string {
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+var parameterReference = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// references returns the sorted names of all ${param} references in the given properties.
+func references(props map[string]string) []string {
+	found := make(map[string]bool)
+	for _, v := range props {
+		for _, match := range parameterReference.FindAllStringSubmatch(v, -1) {
+			found[match[1]] = true
+		}
+	}
+
+	var names []string
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge returns a new map with the properties of all given maps, later ones overriding earlier ones.
+func merge(maps ...map[string]string) map[string]string {
+	result := make(map[string]string)
+	for _, m := range maps {
+		for k, v := range m {
+			result[k] = v
+		}
+	}
+	return result
+}
@@ -0,0 +1,217 @@
+package projectdir
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Severity tells whether a Problem breaks the upload or flows, or is likely a mistake
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+)
+
+// Problem is something wrong with a project found by Validate
+type Problem struct {
+	Severity Severity
+	// File is the file the problem was found in, relative to the project directory
+	File string
+	// Flow is the Flow 2.0 flow the problem was found in, empty for Flow 1.0 projects
+	Flow    string
+	Job     string
+	Message string
+}
+
+func (p Problem) String() string {
+	file := p.File
+	if file == "" {
+		file = "."
+	}
+	return fmt.Sprintf("%s: %s: %s", file, p.Severity, p.Message)
+}
+
+// DefaultJobTypes are the job types of an Azkaban installation with the common job type plugins.
+var DefaultJobTypes = []string{"command", "java", "javaprocess", "hadoopJava", "hadoopShell", "hive", "pig", "spark", "noop", "flow"}
+
+// Validate checks the project for mistakes Azkaban only notices when it executes the flows: missing dependencies,
+// cycles, unknown job types, ${param} references that are never defined, and jobs not connected to any other job.
+// jobTypes are the job types Azkaban knows, DefaultJobTypes if empty. Problems are sorted by file.
+func Validate(project *Project, jobTypes []string) []Problem {
+	if len(jobTypes) == 0 {
+		jobTypes = DefaultJobTypes
+	}
+	v := &validator{project: project, jobTypes: make(map[string]bool)}
+	for _, t := range jobTypes {
+		v.jobTypes[t] = true
+	}
+
+	for _, f := range project.Ignored {
+		if project.FlowVersion == Flow2 {
+			v.add(Warning, f, "", "", "Azkaban ignores .job files in Flow 2.0 projects")
+		} else {
+			v.add(Warning, f, "", "", "Azkaban ignores .flow files without a .project file containing azkaban-flow-version: 2.0")
+		}
+	}
+
+	if project.FlowVersion == Flow2 {
+		if len(project.Flows) == 0 {
+			v.add(Error, "", "", "", "no .flow files found")
+		}
+		for _, f := range project.Flows {
+			v.validateJobs(f.ID, f.Jobs)
+		}
+	} else {
+		if len(project.Jobs) == 0 {
+			v.add(Error, "", "", "", "no .job files found")
+		}
+		v.validateJobs("", project.Jobs)
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool { return v.problems[i].File < v.problems[j].File })
+	return v.problems
+}
+
+type validator struct {
+	project  *Project
+	jobTypes map[string]bool
+	problems []Problem
+}
+
+func (v *validator) add(severity Severity, file string, flow string, job string, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{
+		Severity: severity,
+		File:     file,
+		Flow:     flow,
+		Job:      job,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// validateJobs checks jobs whose dependencies refer to each other: all jobs of a Flow 1.0 project, or the jobs of a
+// Flow 2.0 flow or embedded flow.
+func (v *validator) validateJobs(flow string, jobs []*Job) {
+	byID := make(map[string]*Job)
+	dependedOn := make(map[string]bool)
+	for _, j := range jobs {
+		if other, ok := byID[j.ID]; ok {
+			v.add(Error, j.File, flow, j.ID, "job %s is already defined in %s", j.ID, other.File)
+			continue
+		}
+		byID[j.ID] = j
+		for _, dep := range j.In {
+			dependedOn[dep] = true
+		}
+	}
+
+	for _, j := range jobs {
+		for _, dep := range j.In {
+			if _, ok := byID[dep]; !ok {
+				v.add(Error, j.File, flow, j.ID, "job %s depends on %s which doesn't exist", j.ID, dep)
+			}
+		}
+		// Jobs embedding a flow are often on their own
+		if len(jobs) > 1 && len(j.In) == 0 && !dependedOn[j.ID] && j.Type != "flow" {
+			if v.project.FlowVersion == Flow1 {
+				v.add(Warning, j.File, flow, j.ID, "job %s is not connected to any other job, it becomes a flow of its own", j.ID)
+			} else {
+				v.add(Warning, j.File, flow, j.ID, "job %s is not connected to any other job of its flow", j.ID)
+			}
+		}
+		v.validateJob(flow, j)
+	}
+
+	for _, cycle := range findCycles(jobs, byID) {
+		v.add(Error, byID[cycle[0]].File, flow, cycle[0], "jobs %s depend on each other in a cycle", strings.Join(cycle, " -> "))
+	}
+}
+
+func (v *validator) validateJob(flow string, j *Job) {
+	switch {
+	case j.Type == "":
+		v.add(Error, j.File, flow, j.ID, "job %s has no type", j.ID)
+	case !v.jobTypes[j.Type]:
+		v.add(Error, j.File, flow, j.ID, "job %s has unknown type %s", j.ID, j.Type)
+	}
+
+	if j.Type == "flow" {
+		if v.project.FlowVersion == Flow1 {
+			name := j.Properties["flow.name"]
+			if name == "" {
+				v.add(Error, j.File, flow, j.ID, "job %s embeds a flow but has no flow.name", j.ID)
+			} else if !v.hasFlow(name) {
+				v.add(Error, j.File, flow, j.ID, "job %s embeds flow %s which doesn't exist", j.ID, name)
+			}
+		} else {
+			v.validateJobs(flow, j.Jobs)
+		}
+	}
+
+	defined := merge(j.inherited, j.Properties)
+	for _, name := range references(j.Properties) {
+		if _, ok := defined[name]; ok || isBuiltinParameter(name) {
+			continue
+		}
+		v.add(Warning, j.File, flow, j.ID, "job %s uses ${%s} which is not defined, it has to be passed when executing the flow", j.ID, name)
+	}
+}
+
+func (v *validator) hasFlow(id string) bool {
+	for _, f := range v.project.Flows {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isBuiltinParameter returns true for parameters Azkaban sets for every execution, like ${azkaban.flow.execid}.
+func isBuiltinParameter(name string) bool {
+	return strings.HasPrefix(name, "azkaban.") || name == "working.dir"
+}
+
+// findCycles returns every dependency cycle among the jobs once, as list of job IDs starting and ending with the same
+// job.
+func findCycles(jobs []*Job, byID map[string]*Job) [][]string {
+	const (
+		unvisited = iota
+		inProgress
+		done
+	)
+	state := make(map[string]int)
+	var cycles [][]string
+	var path []string
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = inProgress
+		path = append(path, id)
+		for _, dep := range byID[id].In {
+			if _, ok := byID[dep]; !ok {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case inProgress:
+				start := 0
+				for path[start] != dep {
+					start++
+				}
+				cycle := append([]string{}, path[start:]...)
+				cycles = append(cycles, append(cycle, dep))
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = done
+	}
+
+	for _, j := range jobs {
+		if byID[j.ID] == j && state[j.ID] == unvisited {
+			visit(j.ID)
+		}
+	}
+	return cycles
+}
@@ -0,0 +1,61 @@
+package projectdir
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		dir      string
+		jobTypes []string
+		expected []string
+	}{
+		{
+			dir: "testdata/flow1",
+			expected: []string{
+				"cleanup.job: warning: job cleanup is not connected to any other job, it becomes a flow of its own",
+				"cycle_a.job: error: jobs cycle_a -> cycle_b -> cycle_a depend on each other in a cycle",
+				"embed_missing.job: error: job embed_missing embeds flow missing which doesn't exist",
+				"extract.job: warning: job extract uses ${date} which is not defined, it has to be passed when executing the flow",
+				"load.job: warning: job load uses ${threads} which is not defined, it has to be passed when executing the flow",
+				"report.job: error: job report depends on publish which doesn't exist",
+				"report.job: error: job report has unknown type email",
+			},
+		},
+		{
+			dir:      "testdata/flow1",
+			jobTypes: append([]string{"email"}, DefaultJobTypes...),
+			expected: []string{
+				"cleanup.job: warning: job cleanup is not connected to any other job, it becomes a flow of its own",
+				"cycle_a.job: error: jobs cycle_a -> cycle_b -> cycle_a depend on each other in a cycle",
+				"embed_missing.job: error: job embed_missing embeds flow missing which doesn't exist",
+				"extract.job: warning: job extract uses ${date} which is not defined, it has to be passed when executing the flow",
+				"load.job: warning: job load uses ${threads} which is not defined, it has to be passed when executing the flow",
+				"report.job: error: job report depends on publish which doesn't exist",
+			},
+		},
+		{
+			dir: "testdata/flow2",
+			expected: []string{
+				"daily.flow: warning: job extract uses ${date} which is not defined, it has to be passed when executing the flow",
+				"daily.flow: error: job check depends on missing which doesn't exist",
+				"old.job: warning: Azkaban ignores .job files in Flow 2.0 projects",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		project, err := Load(test.dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var problems []string
+		for _, p := range Validate(project, test.jobTypes) {
+			problems = append(problems, p.String())
+		}
+		if !reflect.DeepEqual(problems, test.expected) {
+			t.Errorf("%s: expected\n%v\ngot\n%v", test.dir, test.expected, problems)
+		}
+	}
+}
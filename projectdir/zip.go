@@ -0,0 +1,101 @@
+// Package projectdir works with Azkaban project directories, the job and flow definitions that are zipped and uploaded
+// to Azkaban.
+package projectdir
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Zip writes the files in the given directory and its subdirectories to w as a zip archive. Hidden files and
+// directories like .git are skipped, except for the .project file of Flow 2.0 projects.
+func Zip(dir string, w io.Writer) error {
+	files, err := projectFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(w)
+	for _, name := range files {
+		if err := addToZip(archive, filepath.Join(dir, filepath.FromSlash(name)), name); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+func addToZip(archive *zip.Writer, path string, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+
+	entry, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(entry, f)
+	return err
+}
+
+// Unzip extracts the zip archive read from r, which is size bytes long, into dir, e.g. to Load a project zip. Entries
+// with absolute paths or paths leading out of dir are rejected.
+func Unzip(r io.ReaderAt, size int64, dir string) error {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range archive.File {
+		name := path.Clean(strings.Replace(f.Name, "\\", "/", -1))
+		if path.IsAbs(name) || name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %q in zip", f.Name)
+		}
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := extractFromZip(f, filepath.Join(dir, filepath.FromSlash(name))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractFromZip(f *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return err
+	}
+	entry, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer entry.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, entry); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,99 @@
+package projectdir
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "projectdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"extract.job":         "type=command\ncommand=echo extract\n",
+		"load/load.job":       "type=command\ndependencies=extract\n",
+		".git/HEAD":           "ref: refs/heads/master\n",
+		".hidden.properties":  "secret=1\n",
+		"common.properties":   "env=prod\n",
+		"load/.DS_Store":      "",
+		"lib/nested/tool.jar": "jar",
+		".project":            "azkaban-flow-version: 2.0\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := Zip(dir, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	expected := []string{".project", "common.properties", "extract.job", "lib/nested/tool.jar", "load/load.job"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+}
+
+func TestUnzip(t *testing.T) {
+	zipOf := func(files map[string]string) *bytes.Reader {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for name, content := range files {
+			entry, err := archive.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entry.Write([]byte(content))
+		}
+		if err := archive.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return bytes.NewReader(buf.Bytes())
+	}
+
+	dir, err := ioutil.TempDir("", "projectdir")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := zipOf(map[string]string{"flows/extract.job": "type=command\n", "flows/": ""})
+	if err := Unzip(r, r.Size(), dir); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, "flows", "extract.job"))
+	if err != nil || string(content) != "type=command\n" {
+		t.Errorf("expected extracted job, got %q, %v", content, err)
+	}
+
+	for _, name := range []string{"../escape.job", "/etc/escape.job", "flows/../../escape.job"} {
+		r := zipOf(map[string]string{name: ""})
+		if err := Unzip(r, r.Size(), dir); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}